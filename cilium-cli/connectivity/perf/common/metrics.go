@@ -7,7 +7,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"maps"
+	"math"
 	"os"
 	"path"
 	"slices"
@@ -25,6 +27,45 @@ type LatencyMetric struct {
 	Perc99 time.Duration `json:"Perc99"`
 }
 
+// NewLatencyMetricFromSamples computes a LatencyMetric from raw per-request
+// durations. Percentiles are computed with linear interpolation between the
+// two nearest ranks of the sorted sample set. Returns nil when no samples are
+// given.
+func NewLatencyMetricFromSamples(samples []time.Duration) *LatencyMetric {
+	if len(samples) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	var sum time.Duration
+	for _, sample := range sorted {
+		sum += sample
+	}
+
+	return &LatencyMetric{
+		Min:    sorted[0],
+		Avg:    sum / time.Duration(len(sorted)),
+		Max:    sorted[len(sorted)-1],
+		Perc50: percentile(sorted, 50),
+		Perc90: percentile(sorted, 90),
+		Perc99: percentile(sorted, 99),
+	}
+}
+
+// percentile returns the p-th percentile of the sorted samples using linear
+// interpolation between the two nearest ranks.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	frac := rank - float64(lower)
+	return sorted[lower] + time.Duration(math.Round(frac*float64(sorted[upper]-sorted[lower])))
+}
+
 // toPerfData export LatencyMetric in a format compatible with perfdash scheme
 func (metric *LatencyMetric) toPerfData(labels map[string]string, prefix string) dataItem {
 	resLabels := map[string]string{
@@ -44,22 +85,71 @@ func (metric *LatencyMetric) toPerfData(labels map[string]string, prefix string)
 	}
 }
 
+// toPerfDataPerPercentile exports each latency percentile as its own dataItem
+// carrying a "percentile" label, instead of the bundled form built by
+// toPerfData. Each percentile then becomes a distinct labeled series, which is
+// what Grafana heatmaps expect.
+func (metric *LatencyMetric) toPerfDataPerPercentile(labels map[string]string, prefix string) []dataItem {
+	percentiles := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"p50", metric.Perc50},
+		{"p90", metric.Perc90},
+		{"p99", metric.Perc99},
+	}
+	items := make([]dataItem, 0, len(percentiles))
+	for _, p := range percentiles {
+		resLabels := map[string]string{
+			"metric": "Latency",
+		}
+		maps.Copy(resLabels, labels)
+		resLabels["percentile"] = p.name
+		items = append(items, dataItem{
+			Data: map[string]float64{
+				prefix + "_" + p.name: float64(p.value) / float64(time.Microsecond),
+			},
+			Unit:   "us",
+			Labels: resLabels,
+		})
+	}
+	return items
+}
+
 // TransactionRateMetric captures transaction rate metric of network performance test
 type TransactionRateMetric struct {
 	TransactionRate float64 `json:"Rate"` // Ops per second
 }
 
+// TransactionRateUnit selects the unit in which TransactionRateMetric is exported.
+type TransactionRateUnit string
+
+const (
+	// TransactionRatePerSecond exports operations per second (default).
+	TransactionRatePerSecond TransactionRateUnit = "ops/s"
+	// TransactionRatePerMinute exports operations per minute.
+	TransactionRatePerMinute TransactionRateUnit = "ops/min"
+)
+
+// scale converts a rate in operations per second to the unit.
+func (u TransactionRateUnit) scale(opsPerSecond float64) float64 {
+	if u == TransactionRatePerMinute {
+		return opsPerSecond * 60
+	}
+	return opsPerSecond
+}
+
 // ToPerfData export TransactionRateMetric in a format compatible with perfdash scheme
-func (metric *TransactionRateMetric) toPerfData(labels map[string]string, prefix string) dataItem {
+func (metric *TransactionRateMetric) toPerfData(labels map[string]string, prefix string, unit TransactionRateUnit) dataItem {
 	resLabels := map[string]string{
 		"metric": "TransactionRate",
 	}
 	maps.Copy(resLabels, labels)
 	return dataItem{
 		Data: map[string]float64{
-			prefix + "_throughput": metric.TransactionRate,
+			prefix + "_throughput": unit.scale(metric.TransactionRate),
 		},
-		Unit:   "ops/s",
+		Unit:   string(unit),
 		Labels: resLabels,
 	}
 }
@@ -69,17 +159,65 @@ type ThroughputMetric struct {
 	Throughput float64 `json:"Throughput"` // Throughput in bytes/s
 }
 
+// ThroughputUnit selects the unit in which ThroughputMetric is exported.
+type ThroughputUnit string
+
+const (
+	// ThroughputMbps exports megabits per second (default).
+	ThroughputMbps ThroughputUnit = "Mb/s"
+	// ThroughputGbps exports gigabits per second.
+	ThroughputGbps ThroughputUnit = "Gb/s"
+	// ThroughputMBps exports megabytes per second.
+	ThroughputMBps ThroughputUnit = "MB/s"
+)
+
+// scale converts a throughput in bits per second to the unit.
+func (u ThroughputUnit) scale(bitsPerSecond float64) float64 {
+	switch u {
+	case ThroughputGbps:
+		return bitsPerSecond / 1000000000
+	case ThroughputMBps:
+		return bitsPerSecond / 8000000
+	default:
+		return bitsPerSecond / 1000000
+	}
+}
+
 // ToPerfData export ThroughputMetric in a format compatible with perfdash scheme
-func (metric *ThroughputMetric) toPerfData(labels map[string]string, prefix string) dataItem {
+func (metric *ThroughputMetric) toPerfData(labels map[string]string, prefix string, unit ThroughputUnit) dataItem {
 	resLabels := map[string]string{
 		"metric": "Throughput",
 	}
 	maps.Copy(resLabels, labels)
 	return dataItem{
 		Data: map[string]float64{
-			prefix + "_throughput": metric.Throughput / 1000000,
+			prefix + "_throughput": unit.scale(metric.Throughput),
 		},
-		Unit:   "Mb/s",
+		Unit:   string(unit),
+		Labels: resLabels,
+	}
+}
+
+// ResourceMetric captures resource usage sampled during a network performance test
+type ResourceMetric struct {
+	CPU    float64 `json:"CPU"`    // CPU usage in millicores
+	Memory float64 `json:"Memory"` // Memory usage in bytes
+}
+
+// toPerfData export ResourceMetric in a format compatible with perfdash scheme
+func (metric *ResourceMetric) toPerfData(labels map[string]string, prefix string) dataItem {
+	resLabels := map[string]string{
+		"metric": "ResourceUsage",
+	}
+	maps.Copy(resLabels, labels)
+	return dataItem{
+		Data: map[string]float64{
+			// CPU in millicores, memory in megabytes, so both buckets stay in
+			// the same order of magnitude on the perfdash graph.
+			prefix + "_cpu": metric.CPU,
+			prefix + "_mem": metric.Memory / 1000000,
+		},
+		Unit:   "mC/MB",
 		Labels: resLabels,
 	}
 }
@@ -90,6 +228,40 @@ type PerfResult struct {
 	Latency               *LatencyMetric
 	TransactionRateMetric *TransactionRateMetric
 	ThroughputMetric      *ThroughputMetric
+	ResourceMetric        *ResourceMetric
+}
+
+// Validate returns an error if the result carries no metric at all, or if any
+// present metric has a negative or NaN value.
+func (r PerfResult) Validate() error {
+	if r.Latency == nil && r.TransactionRateMetric == nil && r.ThroughputMetric == nil {
+		return fmt.Errorf("result contains no metrics")
+	}
+	if r.Latency != nil {
+		for name, value := range map[string]time.Duration{
+			"Min":    r.Latency.Min,
+			"Avg":    r.Latency.Avg,
+			"Max":    r.Latency.Max,
+			"Perc50": r.Latency.Perc50,
+			"Perc90": r.Latency.Perc90,
+			"Perc99": r.Latency.Perc99,
+		} {
+			if value < 0 {
+				return fmt.Errorf("latency %s is negative: %s", name, value)
+			}
+		}
+	}
+	if r.TransactionRateMetric != nil {
+		if rate := r.TransactionRateMetric.TransactionRate; math.IsNaN(rate) || rate < 0 {
+			return fmt.Errorf("transaction rate is invalid: %f", rate)
+		}
+	}
+	if r.ThroughputMetric != nil {
+		if tput := r.ThroughputMetric.Throughput; math.IsNaN(tput) || tput < 0 {
+			return fmt.Errorf("throughput is invalid: %f", tput)
+		}
+	}
+	return nil
 }
 
 // PerfTests stores metadata information about performed test
@@ -103,6 +275,15 @@ type PerfTests struct {
 	Duration time.Duration
 	Streams  uint
 	NetQos   bool
+	// CiliumVersion and DatapathMode describe the environment the test ran
+	// in. When set they are exported as labels, so perfdash can compare runs
+	// across versions and datapath modes.
+	CiliumVersion string
+	DatapathMode  string
+	// Tags are arbitrary key/value pairs exported as labels alongside the
+	// built-in ones (e.g. "kernel=6.1"), for flexible perfdash filtering.
+	// Tags never override built-in labels.
+	Tags map[string]string
 }
 
 // PerfSummary stores combined metadata information and results of test
@@ -140,30 +321,281 @@ func getLabelsForTest(summary PerfSummary) map[string]string {
 	if summary.PerfTest.SameNode {
 		node = "same-node"
 	}
-	return map[string]string{
-		"node":      node,
-		"test_type": summary.PerfTest.Tool,
+	labels := map[string]string{}
+	// Custom tags first: the built-in labels below, and the "metric" label
+	// toPerfData adds, always win on conflict.
+	for k, v := range summary.PerfTest.Tags {
+		if k == "metric" {
+			continue
+		}
+		labels[k] = v
+	}
+	labels["node"] = node
+	labels["test_type"] = summary.PerfTest.Tool
+	if summary.PerfTest.CiliumVersion != "" {
+		labels["cilium_version"] = summary.PerfTest.CiliumVersion
+	}
+	if summary.PerfTest.DatapathMode != "" {
+		labels["datapath_mode"] = summary.PerfTest.DatapathMode
+	}
+	return labels
+}
+
+// Identifier returns a canonical, collision-resistant key for the scenario a
+// summary belongs to, incorporating tool, test, scenario, node placement,
+// stream count and message size. Scenarios that differ in any of these stay
+// distinct under it, unlike the shortened node/tool identifier previously
+// built ad hoc, which merged e.g. runs with different message sizes.
+func (s PerfSummary) Identifier() string {
+	node := "other-node"
+	if s.PerfTest.SameNode {
+		node = "same-node"
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-s%d-m%d",
+		s.PerfTest.Tool, s.PerfTest.Test, s.PerfTest.Scenario, node,
+		s.PerfTest.Streams, s.PerfTest.MsgSize)
+}
+
+// ExportOption customizes how ExportPerfSummaries exports results.
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	throughputUnit      ThroughputUnit
+	transactionRateUnit TransactionRateUnit
+	validationLogger    *slog.Logger
+	writeManifest       bool
+	timestampFormat     string
+	minSamples          int
+	minSamplesLogger    *slog.Logger
+	perScenarioFiles    bool
+	filenamePrefix      string
+	perPercentileLat    bool
+	baselineData        map[string]map[string]float64
+}
+
+// defaultTimestampFormat is the layout used for the timestamp in the exported
+// filename. It is deliberately colon-free: colons are rejected by some
+// filesystems and artifact stores.
+const defaultTimestampFormat = "20060102T150405Z"
+
+// defaultFilenamePrefix is the perfdash-mandated prefix of exported data
+// files.
+const defaultFilenamePrefix = "NetworkPerformance_benchmark"
+
+// WithThroughputUnit selects the unit used to export throughput results.
+func WithThroughputUnit(unit ThroughputUnit) ExportOption {
+	return func(o *exportOptions) {
+		o.throughputUnit = unit
+	}
+}
+
+// WithTransactionRateUnit selects the unit used to export transaction rate results.
+func WithTransactionRateUnit(unit TransactionRateUnit) ExportOption {
+	return func(o *exportOptions) {
+		o.transactionRateUnit = unit
+	}
+}
+
+// WithValidation makes ExportPerfSummaries validate each summary and skip
+// invalid ones, logging the reason to the given logger.
+func WithValidation(logger *slog.Logger) ExportOption {
+	return func(o *exportOptions) {
+		o.validationLogger = logger
+	}
+}
+
+// WithFilenamePrefix overrides the prefix of exported data filenames, so
+// several suites exporting into the same directory produce distinctly named
+// files. ExportPerfSummaries rejects prefixes containing path separators.
+func WithFilenamePrefix(prefix string) ExportOption {
+	return func(o *exportOptions) {
+		o.filenamePrefix = prefix
 	}
 }
 
+// WithPerPercentileLatency exports each latency percentile as a separate data
+// item with a "percentile" label, instead of bundling p50/p90/p99 into one.
+// Grafana heatmaps need one series per percentile to render the distribution.
+func WithPerPercentileLatency() ExportOption {
+	return func(o *exportOptions) {
+		o.perPercentileLat = true
+	}
+}
+
+// WithFilenameTimestampFormat selects the time layout used for the timestamp
+// embedded in the exported filename. The filename prefix (by default the
+// perfdash-mandated "NetworkPerformance_benchmark") is always kept.
+func WithFilenameTimestampFormat(layout string) ExportOption {
+	return func(o *exportOptions) {
+		o.timestampFormat = layout
+	}
+}
+
+// WithMinSamples makes ExportPerfSummaries drop any test/scenario group with
+// fewer than n summaries, logging the dropped groups, so that flaky
+// single-sample runs do not publish misleading data points. n <= 1 keeps all
+// groups.
+func WithMinSamples(n int, logger *slog.Logger) ExportOption {
+	return func(o *exportOptions) {
+		o.minSamples = n
+		o.minSamplesLogger = logger
+	}
+}
+
+// WithPerScenarioFiles makes ExportPerfSummaries write one data file per
+// test/scenario group, with the group name embedded in the filename, instead
+// of a single combined file. Sharded runs can then export concurrently into
+// the same report directory and upload results independently. Each file keeps
+// the perfdash layout.
+func WithPerScenarioFiles() ExportOption {
+	return func(o *exportOptions) {
+		o.perScenarioFiles = true
+	}
+}
+
+// WithBaseline annotates exported data items with the change against a known
+// baseline: for every bucket that has a baseline value recorded under the
+// summary's canonical Identifier, a "<bucket>_delta_pct" bucket is added
+// holding the relative change in percent. Buckets or identifiers absent from
+// the baseline are exported unchanged. This lets perfdash and Grafana show
+// the change without a separate comparison step.
+func WithBaseline(baseline map[string]map[string]float64) ExportOption {
+	return func(o *exportOptions) {
+		o.baselineData = baseline
+	}
+}
+
+// WithRunManifest makes ExportPerfSummaries write a manifest.json sidecar into
+// reportDir, listing the files produced, the number of exported summaries and
+// the run timestamp, so downstream tooling can discover results without
+// globbing.
+func WithRunManifest() ExportOption {
+	return func(o *exportOptions) {
+		o.writeManifest = true
+	}
+}
+
+// runManifest is the schema of the manifest.json sidecar.
+type runManifest struct {
+	Timestamp time.Time `json:"timestamp"`
+	Summaries int       `json:"summaries"`
+	Files     []string  `json:"files"`
+}
+
 // ExportPerfSummaries exports Perfsummary in a format compatible with perfdash
 // and saves results in reportDir directory
-func ExportPerfSummaries(summaries []PerfSummary, reportDir string) error {
+func ExportPerfSummaries(summaries []PerfSummary, reportDir string, opts ...ExportOption) error {
+	options := exportOptions{
+		throughputUnit:      ThroughputMbps,
+		transactionRateUnit: TransactionRatePerSecond,
+		timestampFormat:     defaultTimestampFormat,
+		filenamePrefix:      defaultFilenamePrefix,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if strings.ContainsAny(options.filenamePrefix, `/\`) {
+		return fmt.Errorf("filename prefix %q must not contain path separators", options.filenamePrefix)
+	}
+
+	if options.minSamples > 1 {
+		summaries = filterSparseGroups(summaries, options.minSamples, options.minSamplesLogger)
+	}
+
+	var files []string
+	exported := 0
+	if options.perScenarioFiles {
+		groups := map[string][]PerfSummary{}
+		for _, summary := range summaries {
+			key := summary.PerfTest.Test + "_" + summary.PerfTest.Scenario
+			groups[key] = append(groups[key], summary)
+		}
+		for _, key := range slices.Sorted(maps.Keys(groups)) {
+			data, n := collectDataItems(groups[key], options)
+			exported += n
+			if len(data) == 0 {
+				continue
+			}
+			dataFile, err := exportSummary(perfData{Version: "v1", DataItems: slices.Collect(maps.Values(data))}, reportDir, options.filenamePrefix, key, options.timestampFormat)
+			if err != nil {
+				return err
+			}
+			files = append(files, dataFile)
+		}
+	} else {
+		data, n := collectDataItems(summaries, options)
+		exported = n
+		dataFile, err := exportSummary(perfData{Version: "v1", DataItems: slices.Collect(maps.Values(data))}, reportDir, options.filenamePrefix, "", options.timestampFormat)
+		if err != nil {
+			return err
+		}
+		files = append(files, dataFile)
+	}
+
+	if options.writeManifest {
+		manifest := runManifest{
+			Timestamp: time.Now(),
+			Summaries: exported,
+			Files:     files,
+		}
+		manifestStr, err := prettyPrintJSON(manifest)
+		if err != nil {
+			return fmt.Errorf("error formatting manifest: %v error: %w", manifest, err)
+		}
+		manifestPath := path.Join(reportDir, "manifest.json")
+		if err := os.WriteFile(manifestPath, []byte(manifestStr), 0600); err != nil {
+			return fmt.Errorf("writing to file %v error: %w", manifestPath, err)
+		}
+	}
+
+	return nil
+}
+
+// collectDataItems aggregates the summaries into perfdash data items, keyed
+// by the summary's canonical Identifier, returning them along with the
+// number of summaries that passed validation.
+func collectDataItems(summaries []PerfSummary, options exportOptions) (map[string]dataItem, int) {
 	data := map[string]dataItem{}
+	exported := 0
 	for _, summary := range summaries {
+		if options.validationLogger != nil {
+			if err := summary.Result.Validate(); err != nil {
+				options.validationLogger.Warn("Skipping invalid perf summary",
+					"test", summary.PerfTest.Test,
+					"scenario", summary.PerfTest.Scenario,
+					"error", err,
+				)
+				continue
+			}
+		}
+		exported++
 		labels := getLabelsForTest(summary)
-		identifier := fmt.Sprintf("%s-%s", labels["node"], labels["test_type"])
+		identifier := summary.Identifier()
+		baseline := options.baselineData[identifier]
 		if summary.Result.Latency != nil {
-			res := summary.Result.Latency.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario)
-			if _, ok := data[identifier+"lat"]; !ok {
-				data[identifier+"lat"] = res
+			if options.perPercentileLat {
+				for _, res := range summary.Result.Latency.toPerfDataPerPercentile(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario) {
+					addBaselineDeltas(res, baseline)
+					key := identifier + "lat" + res.Labels["percentile"]
+					if _, ok := data[key]; !ok {
+						data[key] = res
+					} else {
+						maps.Copy(data[key].Data, res.Data)
+					}
+				}
 			} else {
-				maps.Copy(data[identifier+"lat"].Data, res.Data)
+				res := summary.Result.Latency.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario)
+				addBaselineDeltas(res, baseline)
+				if _, ok := data[identifier+"lat"]; !ok {
+					data[identifier+"lat"] = res
+				} else {
+					maps.Copy(data[identifier+"lat"].Data, res.Data)
+				}
 			}
-
 		}
 		if summary.Result.TransactionRateMetric != nil {
-			res := summary.Result.TransactionRateMetric.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario)
+			res := summary.Result.TransactionRateMetric.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario, options.transactionRateUnit)
+			addBaselineDeltas(res, baseline)
 			if _, ok := data[identifier+"tr"]; !ok {
 				data[identifier+"tr"] = res
 			} else {
@@ -172,29 +604,138 @@ func ExportPerfSummaries(summaries []PerfSummary, reportDir string) error {
 
 		}
 		if summary.Result.ThroughputMetric != nil {
-			res := summary.Result.ThroughputMetric.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario)
+			res := summary.Result.ThroughputMetric.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario, options.throughputUnit)
+			// Derived per-stream throughput, showing scaling efficiency as the
+			// stream count grows. Hand-built summaries may carry zero streams,
+			// which would make the division meaningless.
+			if streams := summary.PerfTest.Streams; streams > 0 {
+				res.Data[summary.PerfTest.Test+"_"+summary.PerfTest.Scenario+"_throughput_per_stream"] =
+					options.throughputUnit.scale(summary.Result.ThroughputMetric.Throughput) / float64(streams)
+			}
+			addBaselineDeltas(res, baseline)
 			if _, ok := data[identifier+"th"]; !ok {
 				data[identifier+"th"] = res
 			} else {
 				maps.Copy(data[identifier+"th"].Data, res.Data)
 			}
 		}
+		if summary.Result.ResourceMetric != nil {
+			res := summary.Result.ResourceMetric.toPerfData(labels, summary.PerfTest.Test+"_"+summary.PerfTest.Scenario)
+			addBaselineDeltas(res, baseline)
+			if _, ok := data[identifier+"res"]; !ok {
+				data[identifier+"res"] = res
+			} else {
+				maps.Copy(data[identifier+"res"].Data, res.Data)
+			}
+		}
+	}
+	return data, exported
+}
+
+// addBaselineDeltas adds a "<bucket>_delta_pct" datum for every bucket of the
+// item with a baseline value, holding the relative change in percent. A nil
+// baseline, or a zero baseline value, leaves the item untouched.
+func addBaselineDeltas(item dataItem, baseline map[string]float64) {
+	deltas := map[string]float64{}
+	for bucket, value := range item.Data {
+		base, ok := baseline[bucket]
+		if !ok || base == 0 {
+			continue
+		}
+		deltas[bucket+"_delta_pct"] = (value - base) / base * 100
 	}
-	return exportSummary(perfData{Version: "v1", DataItems: slices.Collect(maps.Values(data))}, reportDir)
+	maps.Copy(item.Data, deltas)
 }
 
-func exportSummary(content perfData, reportDir string) error {
+// GeoMeanScore reduces a suite of summaries to a single comparable number:
+// the geometric mean of each test/scenario group's mean throughput normalized
+// against the baseline value for that group. The baseline is keyed by
+// "Test/Scenario". Groups absent from the baseline, or with a non-positive
+// throughput or baseline, are skipped. Returns 0 when nothing can be scored.
+// The geometric mean is used because it is robust for averaging ratios: a
+// scenario twice as fast and one half as fast cancel out exactly.
+func GeoMeanScore(summaries []PerfSummary, baseline map[string]float64) float64 {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, summary := range summaries {
+		if summary.Result.ThroughputMetric == nil {
+			continue
+		}
+		group := summary.PerfTest.Test + "/" + summary.PerfTest.Scenario
+		sums[group] += summary.Result.ThroughputMetric.Throughput
+		counts[group]++
+	}
+
+	logSum := 0.0
+	scored := 0
+	for group, sum := range sums {
+		base, ok := baseline[group]
+		if !ok || base <= 0 {
+			continue
+		}
+		mean := sum / float64(counts[group])
+		if mean <= 0 || math.IsNaN(mean) {
+			continue
+		}
+		logSum += math.Log(mean / base)
+		scored++
+	}
+
+	if scored == 0 {
+		return 0
+	}
+	return math.Exp(logSum / float64(scored))
+}
+
+// filterSparseGroups drops summaries whose test/scenario group holds fewer
+// than minSamples entries, logging each dropped group once.
+func filterSparseGroups(summaries []PerfSummary, minSamples int, logger *slog.Logger) []PerfSummary {
+	counts := map[string]int{}
+	for _, summary := range summaries {
+		counts[summary.PerfTest.Test+"/"+summary.PerfTest.Scenario]++
+	}
+
+	var kept []PerfSummary
+	logged := map[string]bool{}
+	for _, summary := range summaries {
+		group := summary.PerfTest.Test + "/" + summary.PerfTest.Scenario
+		if counts[group] >= minSamples {
+			kept = append(kept, summary)
+			continue
+		}
+		if logger != nil && !logged[group] {
+			logged[group] = true
+			logger.Warn("Dropping perf scenario with too few samples",
+				"test", summary.PerfTest.Test,
+				"scenario", summary.PerfTest.Scenario,
+				"samples", counts[group],
+				"required", minSamples,
+			)
+		}
+	}
+	return kept
+}
+
+// exportSummary writes the perfdash data file into reportDir and returns the
+// name of the file produced. A non-empty group is embedded in the filename,
+// for per-scenario exports.
+func exportSummary(content perfData, reportDir, prefix, group, timestampFormat string) (string, error) {
 	// this filename needs to be in a specific format for perfdash
-	fileName := strings.Join([]string{"NetworkPerformance_benchmark", time.Now().Format(time.RFC3339)}, "_")
-	filePath := path.Join(reportDir, strings.Join([]string{fileName, "json"}, "."))
+	parts := []string{prefix}
+	if group != "" {
+		parts = append(parts, group)
+	}
+	fileName := strings.Join(append(parts, time.Now().UTC().Format(timestampFormat)), "_")
+	fileName = strings.Join([]string{fileName, "json"}, ".")
+	filePath := path.Join(reportDir, fileName)
 	contentStr, err := prettyPrintJSON(content)
 	if err != nil {
-		return fmt.Errorf("error formatting summary: %v error: %w", content, err)
+		return "", fmt.Errorf("error formatting summary: %v error: %w", content, err)
 	}
 	if err := os.WriteFile(filePath, []byte(contentStr), 0600); err != nil {
-		return fmt.Errorf("writing to file %v error: %w", filePath, err)
+		return "", fmt.Errorf("writing to file %v error: %w", filePath, err)
 	}
-	return nil
+	return fileName, nil
 }
 
 func prettyPrintJSON(data any) (string, error) {
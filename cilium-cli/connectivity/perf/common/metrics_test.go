@@ -0,0 +1,672 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerfResultValidate(t *testing.T) {
+	// All metrics nil
+	require.Error(t, PerfResult{}.Validate())
+
+	// NaN throughput
+	require.Error(t, PerfResult{
+		ThroughputMetric: &ThroughputMetric{Throughput: math.NaN()},
+	}.Validate())
+
+	// Negative transaction rate
+	require.Error(t, PerfResult{
+		TransactionRateMetric: &TransactionRateMetric{TransactionRate: -1},
+	}.Validate())
+
+	// Negative latency
+	require.Error(t, PerfResult{
+		Latency: &LatencyMetric{Min: -time.Millisecond},
+	}.Validate())
+
+	// Valid result with a subset of metrics
+	require.NoError(t, PerfResult{
+		Latency:          NewLatencyMetricFromSamples([]time.Duration{time.Millisecond}),
+		ThroughputMetric: &ThroughputMetric{Throughput: 1000000},
+	}.Validate())
+}
+
+func TestThroughputUnits(t *testing.T) {
+	metric := &ThroughputMetric{Throughput: 2000000000} // 2 Gb/s
+
+	for _, tc := range []struct {
+		unit     ThroughputUnit
+		expected float64
+	}{
+		{unit: ThroughputMbps, expected: 2000},
+		{unit: ThroughputGbps, expected: 2},
+		{unit: ThroughputMBps, expected: 250},
+	} {
+		item := metric.toPerfData(nil, "test", tc.unit)
+		require.Equal(t, string(tc.unit), item.Unit)
+		require.Equal(t, tc.expected, item.Data["test_throughput"])
+	}
+}
+
+func TestTransactionRateUnits(t *testing.T) {
+	metric := &TransactionRateMetric{TransactionRate: 10}
+
+	for _, tc := range []struct {
+		unit     TransactionRateUnit
+		expected float64
+	}{
+		{unit: TransactionRatePerSecond, expected: 10},
+		{unit: TransactionRatePerMinute, expected: 600},
+	} {
+		item := metric.toPerfData(nil, "test", tc.unit)
+		require.Equal(t, string(tc.unit), item.Unit)
+		require.Equal(t, tc.expected, item.Data["test_throughput"])
+	}
+}
+
+func TestNewLatencyMetricFromSamples(t *testing.T) {
+	// No samples
+	require.Nil(t, NewLatencyMetricFromSamples(nil))
+
+	// A single sample is every percentile
+	single := NewLatencyMetricFromSamples([]time.Duration{42 * time.Millisecond})
+	require.Equal(t, &LatencyMetric{
+		Min:    42 * time.Millisecond,
+		Avg:    42 * time.Millisecond,
+		Max:    42 * time.Millisecond,
+		Perc50: 42 * time.Millisecond,
+		Perc90: 42 * time.Millisecond,
+		Perc99: 42 * time.Millisecond,
+	}, single)
+
+	// Percentiles are linearly interpolated between the two nearest ranks.
+	// Samples are deliberately unsorted.
+	metric := NewLatencyMetricFromSamples([]time.Duration{
+		40 * time.Millisecond,
+		10 * time.Millisecond,
+		30 * time.Millisecond,
+		20 * time.Millisecond,
+	})
+	require.Equal(t, &LatencyMetric{
+		Min:    10 * time.Millisecond,
+		Avg:    25 * time.Millisecond,
+		Max:    40 * time.Millisecond,
+		Perc50: 25 * time.Millisecond,                      // rank 1.5
+		Perc90: 37 * time.Millisecond,                      // rank 2.7
+		Perc99: 39*time.Millisecond + 700*time.Microsecond, // rank 2.97
+	}, metric)
+}
+
+func TestExportRunManifest(t *testing.T) {
+	reportDir := t.TempDir()
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+			},
+		},
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_RR", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				TransactionRateMetric: &TransactionRateMetric{TransactionRate: 1000},
+			},
+		},
+	}
+
+	// Without the option, no manifest is produced.
+	require.NoError(t, ExportPerfSummaries(summaries, reportDir))
+	_, err := os.Stat(path.Join(reportDir, "manifest.json"))
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, ExportPerfSummaries(summaries, reportDir, WithRunManifest()))
+
+	raw, err := os.ReadFile(path.Join(reportDir, "manifest.json"))
+	require.NoError(t, err)
+	var manifest runManifest
+	require.NoError(t, json.Unmarshal(raw, &manifest))
+
+	require.Equal(t, 2, manifest.Summaries)
+	require.False(t, manifest.Timestamp.IsZero())
+	require.Len(t, manifest.Files, 1)
+
+	// The referenced data file actually exists in the report directory.
+	_, err = os.Stat(path.Join(reportDir, manifest.Files[0]))
+	require.NoError(t, err)
+}
+
+func TestExportFilenameTimestampFormat(t *testing.T) {
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+			},
+		},
+	}
+
+	exportedFile := func(t *testing.T, reportDir string) string {
+		t.Helper()
+		entries, err := os.ReadDir(reportDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		return entries[0].Name()
+	}
+
+	t.Run("default", func(t *testing.T) {
+		reportDir := t.TempDir()
+		require.NoError(t, ExportPerfSummaries(summaries, reportDir))
+
+		name := exportedFile(t, reportDir)
+		require.Regexp(t, `^NetworkPerformance_benchmark_\d{8}T\d{6}Z\.json$`, name)
+		require.NotContains(t, name, ":")
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		reportDir := t.TempDir()
+		require.NoError(t, ExportPerfSummaries(summaries, reportDir, WithFilenameTimestampFormat("2006-01-02")))
+
+		name := exportedFile(t, reportDir)
+		require.Regexp(t, `^NetworkPerformance_benchmark_\d{4}-\d{2}-\d{2}\.json$`, name)
+	})
+}
+
+func TestExportFilenamePrefix(t *testing.T) {
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+			},
+		},
+	}
+
+	exportedFile := func(t *testing.T, reportDir string) string {
+		t.Helper()
+		entries, err := os.ReadDir(reportDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		return entries[0].Name()
+	}
+
+	t.Run("default", func(t *testing.T) {
+		reportDir := t.TempDir()
+		require.NoError(t, ExportPerfSummaries(summaries, reportDir))
+
+		name := exportedFile(t, reportDir)
+		require.True(t, strings.HasPrefix(name, "NetworkPerformance_benchmark_"), name)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		reportDir := t.TempDir()
+		require.NoError(t, ExportPerfSummaries(summaries, reportDir, WithFilenamePrefix("EncryptionPerformance_benchmark")))
+
+		name := exportedFile(t, reportDir)
+		require.True(t, strings.HasPrefix(name, "EncryptionPerformance_benchmark_"), name)
+	})
+
+	t.Run("path separator rejected", func(t *testing.T) {
+		reportDir := t.TempDir()
+		err := ExportPerfSummaries(summaries, reportDir, WithFilenamePrefix("../escape"))
+		require.ErrorContains(t, err, "path separators")
+
+		entries, err := os.ReadDir(reportDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+}
+
+func TestExportResourceMetric(t *testing.T) {
+	reportDir := t.TempDir()
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+				ResourceMetric:   &ResourceMetric{CPU: 250, Memory: 512e6},
+			},
+		},
+	}
+
+	require.NoError(t, ExportPerfSummaries(summaries, reportDir))
+
+	entries, err := os.ReadDir(reportDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	raw, err := os.ReadFile(path.Join(reportDir, entries[0].Name()))
+	require.NoError(t, err)
+	var data perfData
+	require.NoError(t, json.Unmarshal(raw, &data))
+
+	var resource *dataItem
+	for i, item := range data.DataItems {
+		if item.Labels["metric"] == "ResourceUsage" {
+			resource = &data.DataItems[i]
+		}
+	}
+	require.NotNil(t, resource)
+	require.Equal(t, "mC/MB", resource.Unit)
+	require.Equal(t, 250.0, resource.Data["TCP_STREAM_pod-to-pod_cpu"])
+	require.Equal(t, 512.0, resource.Data["TCP_STREAM_pod-to-pod_mem"])
+}
+
+func TestExportPerScenarioFiles(t *testing.T) {
+	reportDir := t.TempDir()
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result:   PerfResult{ThroughputMetric: &ThroughputMetric{Throughput: 1e9}},
+		},
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod", SameNode: true},
+			Result:   PerfResult{ThroughputMetric: &ThroughputMetric{Throughput: 2e9}},
+		},
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_RR", Scenario: "pod-to-host"},
+			Result:   PerfResult{TransactionRateMetric: &TransactionRateMetric{TransactionRate: 100}},
+		},
+	}
+
+	require.NoError(t, ExportPerfSummaries(summaries, reportDir, WithPerScenarioFiles(), WithRunManifest()))
+
+	entries, err := os.ReadDir(reportDir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() == "manifest.json" {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	require.Len(t, names, 2)
+	for _, name := range names {
+		require.True(t,
+			strings.HasPrefix(name, "NetworkPerformance_benchmark_TCP_STREAM_pod-to-pod_") ||
+				strings.HasPrefix(name, "NetworkPerformance_benchmark_TCP_RR_pod-to-host_"),
+			"unexpected file name %q", name)
+	}
+
+	raw, err := os.ReadFile(path.Join(reportDir, "manifest.json"))
+	require.NoError(t, err)
+	var manifest runManifest
+	require.NoError(t, json.Unmarshal(raw, &manifest))
+	require.Equal(t, 3, manifest.Summaries)
+	require.ElementsMatch(t, names, manifest.Files)
+}
+
+func TestExportThroughputPerStream(t *testing.T) {
+	reportDir := t.TempDir()
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM_MULTI", Scenario: "pod-to-pod", Streams: 4},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+			},
+		},
+		{
+			// Zero streams: no derived value, no division by zero.
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod", SameNode: true},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+			},
+		},
+	}
+
+	require.NoError(t, ExportPerfSummaries(summaries, reportDir))
+
+	entries, err := os.ReadDir(reportDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	raw, err := os.ReadFile(path.Join(reportDir, entries[0].Name()))
+	require.NoError(t, err)
+	var data perfData
+	require.NoError(t, json.Unmarshal(raw, &data))
+
+	buckets := map[string]float64{}
+	for _, item := range data.DataItems {
+		for k, v := range item.Data {
+			buckets[k] = v
+		}
+	}
+	// 1e9 scales to 1000 Mb/s; split across 4 streams.
+	require.Equal(t, 1000.0, buckets["TCP_STREAM_MULTI_pod-to-pod_throughput"])
+	require.Equal(t, 250.0, buckets["TCP_STREAM_MULTI_pod-to-pod_throughput_per_stream"])
+	require.NotContains(t, buckets, "TCP_STREAM_pod-to-pod_throughput_per_stream")
+}
+
+func TestExportPerPercentileLatency(t *testing.T) {
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_RR", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				Latency: &LatencyMetric{
+					Perc50: 100 * time.Microsecond,
+					Perc90: 200 * time.Microsecond,
+					Perc99: 400 * time.Microsecond,
+				},
+			},
+		},
+	}
+
+	export := func(t *testing.T, opts ...ExportOption) perfData {
+		t.Helper()
+		reportDir := t.TempDir()
+		require.NoError(t, ExportPerfSummaries(summaries, reportDir, opts...))
+		entries, err := os.ReadDir(reportDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		raw, err := os.ReadFile(path.Join(reportDir, entries[0].Name()))
+		require.NoError(t, err)
+		var data perfData
+		require.NoError(t, json.Unmarshal(raw, &data))
+		return data
+	}
+
+	// Default: one bundled item without a percentile label.
+	data := export(t)
+	require.Len(t, data.DataItems, 1)
+	require.NotContains(t, data.DataItems[0].Labels, "percentile")
+	require.Len(t, data.DataItems[0].Data, 3)
+
+	// Per-percentile: one item per percentile, each its own labeled series.
+	data = export(t, WithPerPercentileLatency())
+	require.Len(t, data.DataItems, 3)
+	byPercentile := map[string]dataItem{}
+	for _, item := range data.DataItems {
+		byPercentile[item.Labels["percentile"]] = item
+	}
+	require.Len(t, byPercentile, 3)
+	for pct, value := range map[string]float64{"p50": 100, "p90": 200, "p99": 400} {
+		item := byPercentile[pct]
+		require.Equal(t, "Latency", item.Labels["metric"])
+		require.Equal(t, "us", item.Unit)
+		require.Equal(t, map[string]float64{"TCP_RR_pod-to-pod_" + pct: value}, item.Data)
+	}
+}
+
+func TestGetLabelsForTest(t *testing.T) {
+	plain := PerfSummary{
+		PerfTest: PerfTests{Tool: "netperf", SameNode: true},
+	}
+	labels := getLabelsForTest(plain)
+	require.Equal(t, map[string]string{
+		"node":      "same-node",
+		"test_type": "netperf",
+	}, labels)
+
+	annotated := PerfSummary{
+		PerfTest: PerfTests{
+			Tool:          "netperf",
+			CiliumVersion: "1.18.0",
+			DatapathMode:  "native",
+		},
+	}
+	labels = getLabelsForTest(annotated)
+	require.Equal(t, map[string]string{
+		"node":           "other-node",
+		"test_type":      "netperf",
+		"cilium_version": "1.18.0",
+		"datapath_mode":  "native",
+	}, labels)
+}
+
+func TestExportPerfSummariesOpenMetrics(t *testing.T) {
+	summaries := []PerfSummary{
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_RR", Scenario: "pod-to-pod", SameNode: true},
+			Result: PerfResult{
+				Latency: &LatencyMetric{
+					Perc50: 100 * time.Microsecond,
+					Perc90: 200 * time.Microsecond,
+					Perc99: 400 * time.Microsecond,
+				},
+				TransactionRateMetric: &TransactionRateMetric{TransactionRate: 2500},
+			},
+		},
+		{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: 2e9}, // 2000 Mb/s
+			},
+		},
+	}
+
+	var buf strings.Builder
+	require.NoError(t, ExportPerfSummariesOpenMetrics(summaries, &buf))
+	out := buf.String()
+
+	// Parse the exposition back into name/labels -> value.
+	values := map[string]float64{}
+	helpSeen, typeSeen := 0, 0
+	for line := range strings.Lines(out) {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "# HELP"):
+			helpSeen++
+		case strings.HasPrefix(line, "# TYPE"):
+			typeSeen++
+		case line == "# EOF" || line == "":
+		default:
+			series, rawValue, found := strings.Cut(line, "} ")
+			require.True(t, found, "unparsable sample line %q", line)
+			value, err := strconv.ParseFloat(rawValue, 64)
+			require.NoError(t, err)
+			values[series+"}"] = value
+		}
+	}
+
+	require.Equal(t, 3, helpSeen)
+	require.Equal(t, 3, typeSeen)
+	require.Equal(t, float64(100), values[`cilium_network_perf_latency_microseconds{node="same-node",percentile="50",scenario="pod-to-pod",test="TCP_RR",test_type="netperf"}`])
+	require.Equal(t, float64(400), values[`cilium_network_perf_latency_microseconds{node="same-node",percentile="99",scenario="pod-to-pod",test="TCP_RR",test_type="netperf"}`])
+	require.Equal(t, float64(2500), values[`cilium_network_perf_transaction_rate{node="same-node",scenario="pod-to-pod",test="TCP_RR",test_type="netperf"}`])
+	require.Equal(t, float64(2000), values[`cilium_network_perf_throughput{node="other-node",scenario="pod-to-pod",test="TCP_STREAM",test_type="netperf"}`])
+	require.True(t, strings.HasSuffix(out, "# EOF\n"))
+}
+
+func TestFilterSparseGroups(t *testing.T) {
+	stream := func() PerfSummary {
+		return PerfSummary{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod"},
+			Result:   PerfResult{ThroughputMetric: &ThroughputMetric{Throughput: 1e9}},
+		}
+	}
+	rr := func() PerfSummary {
+		return PerfSummary{
+			PerfTest: PerfTests{Tool: "netperf", Test: "TCP_RR", Scenario: "pod-to-pod"},
+			Result:   PerfResult{TransactionRateMetric: &TransactionRateMetric{TransactionRate: 1000}},
+		}
+	}
+	summaries := []PerfSummary{stream(), stream(), stream(), rr()}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	// The single-sample TCP_RR group is dropped, the TCP_STREAM group kept.
+	kept := filterSparseGroups(summaries, 2, logger)
+	require.Len(t, kept, 3)
+	for _, summary := range kept {
+		require.Equal(t, "TCP_STREAM", summary.PerfTest.Test)
+	}
+	require.Contains(t, buf.String(), "Dropping perf scenario with too few samples")
+	require.Contains(t, buf.String(), "TCP_RR")
+
+	// The whole pipeline honors the option.
+	reportDir := t.TempDir()
+	require.NoError(t, ExportPerfSummaries(summaries, reportDir, WithMinSamples(2, logger)))
+	entries, err := os.ReadDir(reportDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	raw, err := os.ReadFile(path.Join(reportDir, entries[0].Name()))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "TransactionRate")
+}
+
+func TestGeoMeanScore(t *testing.T) {
+	mkSummary := func(test, scenario string, throughput float64) PerfSummary {
+		return PerfSummary{
+			PerfTest: PerfTests{Tool: "netperf", Test: test, Scenario: scenario},
+			Result: PerfResult{
+				ThroughputMetric: &ThroughputMetric{Throughput: throughput},
+			},
+		}
+	}
+
+	summaries := []PerfSummary{
+		mkSummary("TCP_STREAM", "pod-to-pod", 2e9),
+		mkSummary("UDP_STREAM", "pod-to-pod", 5e8),
+		// No baseline entry: skipped.
+		mkSummary("TCP_STREAM", "pod-to-host", 3e9),
+	}
+	baseline := map[string]float64{
+		"TCP_STREAM/pod-to-pod": 1e9,
+		"UDP_STREAM/pod-to-pod": 1e9,
+	}
+
+	// Ratios are 2.0 and 0.5; their geometric mean is exactly 1.
+	require.InDelta(t, 1.0, GeoMeanScore(summaries, baseline), 1e-9)
+
+	// Repeated samples for a group are averaged before normalizing.
+	summaries = append(summaries, mkSummary("TCP_STREAM", "pod-to-pod", 6e9))
+	// TCP mean is 4e9 -> ratio 4.0; geomean of 4.0 and 0.5 is sqrt(2).
+	require.InDelta(t, math.Sqrt2, GeoMeanScore(summaries, baseline), 1e-9)
+
+	// Nothing scorable yields zero.
+	require.Zero(t, GeoMeanScore(summaries, nil))
+	require.Zero(t, GeoMeanScore(nil, baseline))
+}
+
+func TestPerfSummaryIdentifier(t *testing.T) {
+	base := PerfSummary{
+		PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod", Streams: 4, MsgSize: 1024},
+	}
+	require.Equal(t, "netperf-TCP_STREAM-pod-to-pod-other-node-s4-m1024", base.Identifier())
+
+	// Any distinguishing dimension produces a distinct identifier.
+	variants := []PerfSummary{base, base, base, base, base, base}
+	variants[1].PerfTest.Tool = "iperf"
+	variants[2].PerfTest.Test = "TCP_RR"
+	variants[3].PerfTest.Scenario = "pod-to-host"
+	variants[4].PerfTest.SameNode = true
+	variants[5].PerfTest.MsgSize = 8192
+	seen := map[string]bool{}
+	for _, v := range variants {
+		seen[v.Identifier()] = true
+	}
+	require.Len(t, seen, len(variants))
+}
+
+func TestGetLabelsForTestTags(t *testing.T) {
+	summary := PerfSummary{
+		PerfTest: PerfTests{
+			Tool:     "netperf",
+			Test:     "TCP_STREAM",
+			Scenario: "pod-to-pod",
+			Tags: map[string]string{
+				"kernel":    "6.1",
+				"region":    "us-east",
+				"node":      "spoofed",
+				"test_type": "spoofed",
+				"metric":    "spoofed",
+			},
+		},
+		Result: PerfResult{ThroughputMetric: &ThroughputMetric{Throughput: 1e9}},
+	}
+
+	labels := getLabelsForTest(summary)
+	// Custom tags appear as labels.
+	require.Equal(t, "6.1", labels["kernel"])
+	require.Equal(t, "us-east", labels["region"])
+	// Reserved labels win over colliding tags.
+	require.Equal(t, "other-node", labels["node"])
+	require.Equal(t, "netperf", labels["test_type"])
+
+	// The "metric" label toPerfData adds is protected too.
+	item := summary.Result.ThroughputMetric.toPerfData(labels, "test", ThroughputMbps)
+	require.Equal(t, "Throughput", item.Labels["metric"])
+	require.Equal(t, "6.1", item.Labels["kernel"])
+}
+
+func TestExportBaselineDeltas(t *testing.T) {
+	matched := PerfSummary{
+		PerfTest: PerfTests{Tool: "netperf", Test: "TCP_STREAM", Scenario: "pod-to-pod", Streams: 1},
+		Result: PerfResult{
+			ThroughputMetric: &ThroughputMetric{Throughput: 1e9},
+		},
+	}
+	latency := PerfSummary{
+		PerfTest: PerfTests{Tool: "netperf", Test: "TCP_CRR", Scenario: "pod-to-pod"},
+		Result: PerfResult{
+			Latency: &LatencyMetric{Perc50: 200 * time.Microsecond, Perc90: 300 * time.Microsecond, Perc99: 400 * time.Microsecond},
+		},
+	}
+	unmatched := PerfSummary{
+		PerfTest: PerfTests{Tool: "netperf", Test: "TCP_RR", Scenario: "pod-to-pod"},
+		Result: PerfResult{
+			TransactionRateMetric: &TransactionRateMetric{TransactionRate: 1000},
+		},
+	}
+
+	// 1e9 bits/s scales to 1000 Mb/s; a baseline of 800 means +25%.
+	// The 200us p50 against a baseline of 160us is +25% as well.
+	baseline := map[string]map[string]float64{
+		matched.Identifier(): {
+			"TCP_STREAM_pod-to-pod_throughput": 800,
+		},
+		latency.Identifier(): {
+			"TCP_CRR_pod-to-pod_p50": 160,
+		},
+	}
+
+	readBuckets := func(t *testing.T, reportDir string) map[string]float64 {
+		t.Helper()
+		entries, err := os.ReadDir(reportDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		raw, err := os.ReadFile(path.Join(reportDir, entries[0].Name()))
+		require.NoError(t, err)
+		var data perfData
+		require.NoError(t, json.Unmarshal(raw, &data))
+
+		buckets := map[string]float64{}
+		for _, item := range data.DataItems {
+			for k, v := range item.Data {
+				buckets[k] = v
+			}
+		}
+		return buckets
+	}
+
+	reportDir := t.TempDir()
+	require.NoError(t, ExportPerfSummaries([]PerfSummary{matched, latency, unmatched}, reportDir, WithBaseline(baseline)))
+
+	buckets := readBuckets(t, reportDir)
+	require.InDelta(t, 25.0, buckets["TCP_STREAM_pod-to-pod_throughput_delta_pct"], 1e-9)
+	require.Equal(t, 1000.0, buckets["TCP_STREAM_pod-to-pod_throughput"])
+	// Latency buckets get deltas too, for the percentiles with a baseline.
+	require.InDelta(t, 25.0, buckets["TCP_CRR_pod-to-pod_p50_delta_pct"], 1e-9)
+	require.NotContains(t, buckets, "TCP_CRR_pod-to-pod_p90_delta_pct")
+	// Identifiers without a baseline entry get no delta bucket.
+	require.NotContains(t, buckets, "TCP_RR_pod-to-pod_throughput_delta_pct")
+
+	// The per-percentile latency form carries the delta as well.
+	perPercentileDir := t.TempDir()
+	require.NoError(t, ExportPerfSummaries([]PerfSummary{latency}, perPercentileDir, WithBaseline(baseline), WithPerPercentileLatency()))
+	buckets = readBuckets(t, perPercentileDir)
+	require.InDelta(t, 25.0, buckets["TCP_CRR_pod-to-pod_p50_delta_pct"], 1e-9)
+	require.NotContains(t, buckets, "TCP_CRR_pod-to-pod_p90_delta_pct")
+}
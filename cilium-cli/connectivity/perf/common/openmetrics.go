@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package common
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Metric family names used by the OpenMetrics export.
+const (
+	openMetricsLatencyName         = "cilium_network_perf_latency_microseconds"
+	openMetricsTransactionRateName = "cilium_network_perf_transaction_rate"
+	openMetricsThroughputName      = "cilium_network_perf_throughput"
+)
+
+// ExportPerfSummariesOpenMetrics writes the performance summaries to w in the
+// OpenMetrics text exposition format, as an alternative to the perfdash JSON
+// produced by ExportPerfSummaries. Each sample carries the labels from
+// getLabelsForTest plus the test and scenario, and uses the default unit
+// conventions (microseconds, operations per second, megabits per second).
+func ExportPerfSummariesOpenMetrics(summaries []PerfSummary, w io.Writer) error {
+	type sample struct {
+		labels map[string]string
+		value  float64
+	}
+	families := map[string][]sample{}
+
+	add := func(name string, labels map[string]string, value float64) {
+		families[name] = append(families[name], sample{labels: labels, value: value})
+	}
+
+	for _, summary := range summaries {
+		labels := getLabelsForTest(summary)
+		labels["test"] = summary.PerfTest.Test
+		labels["scenario"] = summary.PerfTest.Scenario
+
+		if summary.Result.Latency != nil {
+			for percentile, value := range map[string]time.Duration{
+				"50": summary.Result.Latency.Perc50,
+				"90": summary.Result.Latency.Perc90,
+				"99": summary.Result.Latency.Perc99,
+			} {
+				withPercentile := map[string]string{"percentile": percentile}
+				for k, v := range labels {
+					withPercentile[k] = v
+				}
+				add(openMetricsLatencyName, withPercentile, float64(value)/float64(time.Microsecond))
+			}
+		}
+		if summary.Result.TransactionRateMetric != nil {
+			add(openMetricsTransactionRateName, labels, TransactionRatePerSecond.scale(summary.Result.TransactionRateMetric.TransactionRate))
+		}
+		if summary.Result.ThroughputMetric != nil {
+			add(openMetricsThroughputName, labels, ThroughputMbps.scale(summary.Result.ThroughputMetric.Throughput))
+		}
+	}
+
+	help := map[string]string{
+		openMetricsLatencyName:         "Latency percentiles of network performance tests in microseconds.",
+		openMetricsTransactionRateName: "Transaction rate of network performance tests in operations per second.",
+		openMetricsThroughputName:      "Throughput of network performance tests in megabits per second.",
+	}
+
+	for _, name := range []string{openMetricsLatencyName, openMetricsTransactionRateName, openMetricsThroughputName} {
+		samples := families[name]
+		if len(samples) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help[name], name); err != nil {
+			return err
+		}
+		for _, s := range samples {
+			if _, err := fmt.Fprintf(w, "%s{%s} %s\n", name, formatOpenMetricsLabels(s.labels), strconv.FormatFloat(s.value, 'g', -1, 64)); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
+
+// formatOpenMetricsLabels renders labels sorted by name, so the output is
+// deterministic.
+func formatOpenMetricsLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := ""
+	for i, name := range names {
+		if i > 0 {
+			out += ","
+		}
+		out += fmt.Sprintf("%s=%q", name, labels[name])
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package asm
+
+import "testing"
+
+func TestMovOpCodes(t *testing.T) {
+	testcases := []struct {
+		name string
+		have func(Class) (OpCode, error)
+		cls  Class
+		want OpCode
+	}{
+		{"MovReg ALU64", MovReg, ALU64Class, Mov.Op(RegSource)},
+		{"MovReg ALU32", MovReg, ALUClass, Mov.Op32(RegSource)},
+		{"MovImm ALU64", MovImm, ALU64Class, Mov.Op(ImmSource)},
+		{"MovImm ALU32", MovImm, ALUClass, Mov.Op32(ImmSource)},
+	}
+
+	for _, tc := range testcases {
+		op, err := tc.have(tc.cls)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		if op != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, op, tc.want)
+		}
+	}
+
+	// Non-ALU classes are rejected.
+	for _, cls := range []Class{JumpClass, Jump32Class, LdClass, StXClass} {
+		if op, err := MovReg(cls); err == nil {
+			t.Errorf("MovReg(%v) = %v, want an error", cls, op)
+		}
+		if op, err := MovImm(cls); err == nil {
+			t.Errorf("MovImm(%v) = %v, want an error", cls, op)
+		}
+	}
+}
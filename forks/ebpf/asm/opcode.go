@@ -0,0 +1,849 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -output opcode_string.go -type=Class
+
+// Class of operations
+//
+//	msb      lsb
+//	+---+--+---+
+//	|  ??  |CLS|
+//	+---+--+---+
+type Class uint8
+
+const classMask OpCode = 0x07
+
+const (
+	// LdClass loads immediate values into registers.
+	// Also used for non-standard load operations from cBPF.
+	LdClass Class = 0x00
+	// LdXClass loads memory into registers.
+	LdXClass Class = 0x01
+	// StClass stores immediate values to memory.
+	StClass Class = 0x02
+	// StXClass stores registers to memory.
+	StXClass Class = 0x03
+	// ALUClass describes arithmetic operators.
+	ALUClass Class = 0x04
+	// JumpClass describes jump operators.
+	JumpClass Class = 0x05
+	// Jump32Class describes jump operators with 32-bit comparisons.
+	// Requires kernel 5.1.
+	Jump32Class Class = 0x06
+	// ALU64Class describes arithmetic operators in 64-bit mode.
+	ALU64Class Class = 0x07
+)
+
+// IsLoad checks if this is either LdClass or LdXClass.
+func (cls Class) IsLoad() bool {
+	return cls == LdClass || cls == LdXClass
+}
+
+// IsStore checks if this is either StClass or StXClass.
+func (cls Class) IsStore() bool {
+	return cls == StClass || cls == StXClass
+}
+
+func (cls Class) isLoadOrStore() bool {
+	return cls.IsLoad() || cls.IsStore()
+}
+
+// IsALU checks if this is either ALUClass or ALU64Class.
+func (cls Class) IsALU() bool {
+	return cls == ALUClass || cls == ALU64Class
+}
+
+// IsJump checks if this is either JumpClass or Jump32Class.
+func (cls Class) IsJump() bool {
+	return cls == JumpClass || cls == Jump32Class
+}
+
+func (cls Class) isJumpOrALU() bool {
+	return cls.IsJump() || cls.IsALU()
+}
+
+// OpCode represents a single operation.
+// It is not a 1:1 mapping to real eBPF opcodes.
+//
+// The encoding varies based on a 3-bit Class:
+//
+//	7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0
+//	                          ???                            | CLS
+//
+// For ALUClass and ALUCLass32:
+//
+//	7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0
+//	             0                 |           OPC         |S| CLS
+//
+// For LdClass, LdXclass, StClass and StXClass:
+//
+//	7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0
+//	                       0                       | MDE |SIZ| CLS
+//
+// For StXClass where MDE == AtomicMode:
+//
+//	7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0
+//	              0              |    ATOMIC OP    | MDE |SIZ| CLS
+//
+// For JumpClass, Jump32Class:
+//
+//	7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0 7 6 5 4 3 2 1 0
+//	                       0                       |  OPC  |S| CLS
+type OpCode uint32
+
+// InvalidOpCode is returned by setters on OpCode
+const InvalidOpCode OpCode = 0xffff
+
+// bpfOpCode returns the actual BPF opcode.
+func (op OpCode) bpfOpCode() (byte, error) {
+	const opCodeMask = 0xff
+
+	if !valid(op, opCodeMask) {
+		return 0, fmt.Errorf("invalid opcode %x", op)
+	}
+
+	return byte(op & opCodeMask), nil
+}
+
+// rawInstructions returns the number of BPF instructions required
+// to encode this opcode.
+func (op OpCode) rawInstructions() int {
+	if op.IsDWordLoad() {
+		return 2
+	}
+	return 1
+}
+
+// RawByteLen returns the number of bytes required to encode this opcode in
+// the kernel's wire format, accounting for the second instruction slot a
+// 64-bit immediate load occupies.
+func (op OpCode) RawByteLen() int {
+	return op.rawInstructions() * InstructionSize
+}
+
+func (op OpCode) IsDWordLoad() bool {
+	return op == LoadImmOp(DWord)
+}
+
+// IsImm64Load returns true if op encodes a 64-bit immediate load.
+//
+// This is the only eBPF instruction occupying two instruction slots: the
+// second slot holds the upper 32 bits of the immediate (or, for pseudo
+// variants like map references, additional metadata). Tools iterating raw
+// instructions must skip the second slot when they encounter this opcode.
+//
+// It is an alias for IsDWordLoad with a name matching the kernel's
+// BPF_LD | BPF_IMM | BPF_DW terminology.
+func (op OpCode) IsImm64Load() bool {
+	return op.IsDWordLoad()
+}
+
+// IsSignExtendingLoad returns true if op encodes a load from memory with sign
+// extension (BPF_MEMSX).
+//
+// Sign-extending loads require kernel 6.4 (cpu v4).
+func (op OpCode) IsSignExtendingLoad() bool {
+	return op.Class().IsLoad() && op.Mode() == MemSXMode
+}
+
+// MinKernelVersion returns a best-effort minimum kernel version required to
+// execute this opcode, like "5.1", or the empty string for baseline opcodes
+// supported since the introduction of eBPF.
+func (op OpCode) MinKernelVersion() string {
+	switch {
+	case op.Class() == Jump32Class:
+		return "5.1"
+	case op.IsSignExtendingLoad():
+		// Part of the cpu v4 instruction set.
+		return "6.4"
+	case op.Mode() == AtomicMode && op.AtomicOp() != AddAtomic:
+		// BPF_XADD predates the named atomics; everything else arrived with
+		// the BPF_ATOMIC encoding.
+		return "5.12"
+	}
+	return ""
+}
+
+// Class returns the class of operation.
+func (op OpCode) Class() Class {
+	return Class(op & classMask)
+}
+
+// Mode returns the mode for load and store operations.
+func (op OpCode) Mode() Mode {
+	if !op.Class().isLoadOrStore() {
+		return InvalidMode
+	}
+	return Mode(op & modeMask)
+}
+
+// Size returns the size for load and store operations.
+func (op OpCode) Size() Size {
+	if !op.Class().isLoadOrStore() {
+		return InvalidSize
+	}
+	return Size(op & sizeMask)
+}
+
+// AtomicOp returns the type of atomic operation.
+func (op OpCode) AtomicOp() AtomicOp {
+	if op.Class() != StXClass || op.Mode() != AtomicMode {
+		return InvalidAtomic
+	}
+	return AtomicOp(op & atomicMask)
+}
+
+// IsAtomicAdd returns true if the OpCode is an atomic add, with or without
+// fetch semantics.
+func (op OpCode) IsAtomicAdd() bool {
+	a := op.AtomicOp()
+	return a == AddAtomic || a == FetchAdd
+}
+
+// IsAtomicAnd returns true if the OpCode is an atomic bitwise AND, with or
+// without fetch semantics.
+func (op OpCode) IsAtomicAnd() bool {
+	a := op.AtomicOp()
+	return a == AndAtomic || a == FetchAnd
+}
+
+// IsAtomicOr returns true if the OpCode is an atomic bitwise OR, with or
+// without fetch semantics.
+func (op OpCode) IsAtomicOr() bool {
+	a := op.AtomicOp()
+	return a == OrAtomic || a == FetchOr
+}
+
+// IsAtomicXor returns true if the OpCode is an atomic bitwise XOR, with or
+// without fetch semantics.
+func (op OpCode) IsAtomicXor() bool {
+	a := op.AtomicOp()
+	return a == XorAtomic || a == FetchXor
+}
+
+// IsAtomicXchg returns true if the OpCode is an atomic exchange.
+func (op OpCode) IsAtomicXchg() bool {
+	return op.AtomicOp() == Xchg
+}
+
+// IsAtomicCmpXchg returns true if the OpCode is an atomic compare-and-exchange.
+func (op OpCode) IsAtomicCmpXchg() bool {
+	return op.AtomicOp() == CmpXchg
+}
+
+// IsSignedJump returns true if the OpCode is a conditional jump using a
+// signed comparison (JSGT, JSGE, JSLT, JSLE), in either the 64- or 32-bit
+// jump class. Signedness is encoded in the opcode; whether the operands are
+// pointers is not, so this only determines how the comparison direction must
+// be interpreted.
+func (op OpCode) IsSignedJump() bool {
+	switch op.JumpOp() {
+	case JSGT, JSGE, JSLT, JSLE:
+		return true
+	default:
+		return false
+	}
+}
+
+// NegateJump returns the opcode with the jump operation replaced by its
+// logical complement (JEq↔JNE, JGT↔JLE, and so on), preserving the jump
+// class and source. Inverting a branch this way is a common peephole
+// transform.
+//
+// Returns an error for opcodes that are not conditional jumps (Ja, Call,
+// Exit) and for JSet, whose complement has no encoding.
+func (op OpCode) NegateJump() (OpCode, error) {
+	var negated JumpOp
+	switch op.JumpOp() {
+	case JEq:
+		negated = JNE
+	case JNE:
+		negated = JEq
+	case JGT:
+		negated = JLE
+	case JLE:
+		negated = JGT
+	case JGE:
+		negated = JLT
+	case JLT:
+		negated = JGE
+	case JSGT:
+		negated = JSLE
+	case JSLE:
+		negated = JSGT
+	case JSGE:
+		negated = JSLT
+	case JSLT:
+		negated = JSGE
+	case JSet:
+		return InvalidOpCode, fmt.Errorf("%v has no complementary jump op", op)
+	default:
+		return InvalidOpCode, fmt.Errorf("%v is not a conditional jump", op)
+	}
+	return op.SetJumpOp(negated), nil
+}
+
+// Source returns the source for branch and ALU operations.
+func (op OpCode) Source() Source {
+	if !op.Class().isJumpOrALU() || op.ALUOp() == Swap {
+		return InvalidSource
+	}
+	return Source(op & sourceMask)
+}
+
+// ALUOp returns the ALUOp.
+func (op OpCode) ALUOp() ALUOp {
+	if !op.Class().IsALU() {
+		return InvalidALUOp
+	}
+	return ALUOp(op & aluMask)
+}
+
+// Endianness returns the Endianness for a byte swap instruction.
+func (op OpCode) Endianness() Endianness {
+	if op.ALUOp() != Swap {
+		return InvalidEndian
+	}
+	return Endianness(op & endianMask)
+}
+
+// JumpOp returns the JumpOp.
+// Returns InvalidJumpOp if it doesn't encode a jump.
+func (op OpCode) JumpOp() JumpOp {
+	if !op.Class().IsJump() {
+		return InvalidJumpOp
+	}
+
+	jumpOp := JumpOp(op & jumpMask)
+
+	// Some JumpOps are only supported by JumpClass, not Jump32Class.
+	if op.Class() == Jump32Class && (jumpOp == Exit || jumpOp == Call) {
+		return InvalidJumpOp
+	}
+
+	return jumpOp
+}
+
+// SetMode sets the mode on load and store operations.
+//
+// Returns InvalidOpCode if op is of the wrong class.
+func (op OpCode) SetMode(mode Mode) OpCode {
+	if !op.Class().isLoadOrStore() || !valid(OpCode(mode), modeMask) {
+		return InvalidOpCode
+	}
+	return (op & ^modeMask) | OpCode(mode)
+}
+
+// SetSize sets the size on load and store operations.
+//
+// Returns InvalidOpCode if op is of the wrong class.
+func (op OpCode) SetSize(size Size) OpCode {
+	if !op.Class().isLoadOrStore() || !valid(OpCode(size), sizeMask) {
+		return InvalidOpCode
+	}
+	return (op & ^sizeMask) | OpCode(size)
+}
+
+func (op OpCode) SetAtomicOp(atomic AtomicOp) OpCode {
+	if op.Class() != StXClass || op.Mode() != AtomicMode || !valid(OpCode(atomic), atomicMask) {
+		return InvalidOpCode
+	}
+	return (op & ^atomicMask) | OpCode(atomic)
+}
+
+// SetSource sets the source on jump and ALU operations.
+//
+// Returns InvalidOpCode if op is of the wrong class.
+func (op OpCode) SetSource(source Source) OpCode {
+	if !op.Class().isJumpOrALU() || !valid(OpCode(source), sourceMask) {
+		return InvalidOpCode
+	}
+	return (op & ^sourceMask) | OpCode(source)
+}
+
+// WithSource returns a copy of op with the source flag replaced by source,
+// converting e.g. the register variant of an ALU or jump operation into its
+// immediate variant or back.
+//
+// It is the error-returning counterpart to SetSource. Swap is rejected: its
+// source bit selects the byte order to convert to, not an operand kind. The
+// jump operations without operands (Exit, Call, Ja) are rejected as well,
+// since flipping their source bit produces an encoding the kernel refuses.
+func (op OpCode) WithSource(source Source) (OpCode, error) {
+	if op.ALUOp() == Swap {
+		return InvalidOpCode, fmt.Errorf("%v has no source to set", op)
+	}
+	if jop := op.JumpOp(); jop == Exit || jop == Call || jop == Ja {
+		return InvalidOpCode, fmt.Errorf("%v has no source to set", op)
+	}
+	newOp := op.SetSource(source)
+	if newOp == InvalidOpCode {
+		return InvalidOpCode, fmt.Errorf("cannot set source %v on %v", source, op)
+	}
+	return newOp, nil
+}
+
+// EqualIgnoringSource returns true if op and other encode the same operation,
+// disregarding the source flag on ALU and jump instructions.
+//
+// This treats e.g. the register and immediate variants of JEq as equal, which
+// is useful when matching instruction shapes by operation type. Swap is
+// compared exactly: its source bit selects the byte order to convert to, not
+// an operand kind, so SwapBE and SwapLE remain distinct.
+func (op OpCode) EqualIgnoringSource(other OpCode) bool {
+	if op.Class().isJumpOrALU() && op.Class() == other.Class() && op.ALUOp() != Swap {
+		return op & ^sourceMask == other & ^sourceMask
+	}
+	return op == other
+}
+
+// SetALUOp sets the ALUOp on ALU operations.
+//
+// Returns InvalidOpCode if op is of the wrong class.
+func (op OpCode) SetALUOp(alu ALUOp) OpCode {
+	if !op.Class().IsALU() || !valid(OpCode(alu), aluMask) {
+		return InvalidOpCode
+	}
+	return (op & ^aluMask) | OpCode(alu)
+}
+
+// IsCall returns true if the OpCode encodes a call instruction.
+//
+// The opcode alone does not distinguish the kind of call: helper calls,
+// BPF-to-BPF pseudo calls and kfunc calls all share the same opcode and
+// differ only in the instruction's source register and immediate. Callers
+// that need to tell them apart must inspect those fields instead of relying
+// on JumpOp() == Call.
+//
+// Call is only valid in JumpClass; a Jump32Class opcode never encodes a
+// call, mirroring JumpOp's rejection of Call for that class.
+func (op OpCode) IsCall() bool {
+	return op.JumpOp() == Call
+}
+
+// WithClass returns a copy of op with the class bits replaced by cls.
+//
+// Only transitions between compatible classes are allowed: ALUClass and
+// ALU64Class, JumpClass and Jump32Class, LdClass and LdXClass, StClass and
+// StXClass. The resulting opcode is validated, so e.g. converting an Exit or
+// Call to Jump32Class fails since those only exist in JumpClass.
+func (op OpCode) WithClass(cls Class) (OpCode, error) {
+	old := op.Class()
+	switch {
+	case old == cls:
+		return op, nil
+	case old.IsALU() && cls.IsALU():
+	case old.IsJump() && cls.IsJump():
+	case old.IsLoad() && cls.IsLoad():
+	case old.IsStore() && cls.IsStore():
+	default:
+		return InvalidOpCode, fmt.Errorf("cannot convert %v from %v to %v", op, old, cls)
+	}
+
+	newOp := (op & ^classMask) | OpCode(cls)
+
+	// Re-validate the result: some operations only exist in one of the
+	// two compatible classes.
+	switch {
+	case cls.IsJump():
+		if newOp.JumpOp() == InvalidJumpOp {
+			return InvalidOpCode, fmt.Errorf("%v is not encodable in %v", op.JumpOp(), cls)
+		}
+
+	case cls.IsALU():
+		// Swap means different things in the two ALU classes: an endianness
+		// conversion in ALUClass, an unconditional byte swap in ALU64Class.
+		// Moving it between them would silently change semantics.
+		if op.ALUOp() == Swap {
+			return InvalidOpCode, fmt.Errorf("cannot convert %v: swap semantics differ between ALU classes", op)
+		}
+
+	case cls.isLoadOrStore():
+		if !newOp.Mode().validInClass(cls) {
+			return InvalidOpCode, fmt.Errorf("%v is not encodable in %v", op.Mode(), cls)
+		}
+	}
+
+	return newOp, nil
+}
+
+// validInClass returns true if the mode is encodable in the given load or
+// store class.
+func (mode Mode) validInClass(cls Class) bool {
+	switch mode {
+	case ImmMode, AbsMode, IndMode:
+		// Immediate and the non-standard cBPF packet loads only exist in
+		// LdClass.
+		return cls == LdClass
+	case MemSXMode:
+		// Sign-extending loads are register loads.
+		return cls == LdXClass
+	case AtomicMode:
+		return cls == StXClass
+	case MemMode:
+		return cls.isLoadOrStore()
+	default:
+		return false
+	}
+}
+
+// SetJumpOp sets the JumpOp on jump operations.
+//
+// Returns InvalidOpCode if op is of the wrong class.
+func (op OpCode) SetJumpOp(jump JumpOp) OpCode {
+	if !op.Class().IsJump() || !valid(OpCode(jump), jumpMask) {
+		return InvalidOpCode
+	}
+
+	newOp := (op & ^jumpMask) | OpCode(jump)
+
+	// Check newOp is legal.
+	if newOp.JumpOp() == InvalidJumpOp {
+		return InvalidOpCode
+	}
+
+	return newOp
+}
+
+func (op OpCode) String() string {
+	var f strings.Builder
+
+	switch class := op.Class(); {
+	case class.isLoadOrStore():
+		f.WriteString(strings.TrimSuffix(class.String(), "Class"))
+
+		mode := op.Mode()
+		f.WriteString(strings.TrimSuffix(mode.String(), "Mode"))
+
+		if atomic := op.AtomicOp(); atomic != InvalidAtomic {
+			f.WriteString(strings.TrimSuffix(atomic.String(), "Atomic"))
+		}
+
+		switch op.Size() {
+		case DWord:
+			f.WriteString("DW")
+		case Word:
+			f.WriteString("W")
+		case Half:
+			f.WriteString("H")
+		case Byte:
+			f.WriteString("B")
+		}
+
+	case class.IsALU():
+		if op.ALUOp() == Swap && op.Class() == ALU64Class {
+			// B to make BSwap, uncontitional byte swap
+			f.WriteString("B")
+		}
+
+		f.WriteString(op.ALUOp().String())
+
+		if op.ALUOp() == Swap {
+			if op.Class() == ALUClass {
+				// Width for Endian is controlled by Constant
+				f.WriteString(op.Endianness().String())
+			}
+		} else {
+			f.WriteString(strings.TrimSuffix(op.Source().String(), "Source"))
+
+			if class == ALUClass {
+				f.WriteString("32")
+			}
+		}
+
+	case class.IsJump():
+		f.WriteString(op.JumpOp().String())
+
+		if class == Jump32Class {
+			f.WriteString("32")
+		}
+
+		if jop := op.JumpOp(); jop != Exit && jop != Call && jop != Ja {
+			f.WriteString(strings.TrimSuffix(op.Source().String(), "Source"))
+		}
+
+	default:
+		fmt.Fprintf(&f, "OpCode(%#x)", uint8(op))
+	}
+
+	return f.String()
+}
+
+// StringVerbose renders the OpCode like String, but always disambiguates the
+// operation width: ALU and jump operations carry an explicit "32" or "64"
+// suffix, and load/store sizes are spelled out in bits instead of the
+// B/H/W/DW shorthand. This produces a canonical textual form that can be
+// compared across mixed 32/64-bit disassembly without ambiguity.
+func (op OpCode) StringVerbose() string {
+	var f strings.Builder
+
+	switch class := op.Class(); {
+	case class.isLoadOrStore():
+		f.WriteString(strings.TrimSuffix(class.String(), "Class"))
+
+		mode := op.Mode()
+		f.WriteString(strings.TrimSuffix(mode.String(), "Mode"))
+
+		if atomic := op.AtomicOp(); atomic != InvalidAtomic {
+			f.WriteString(strings.TrimSuffix(atomic.String(), "Atomic"))
+		}
+
+		switch op.Size() {
+		case DWord:
+			f.WriteString("64")
+		case Word:
+			f.WriteString("32")
+		case Half:
+			f.WriteString("16")
+		case Byte:
+			f.WriteString("8")
+		}
+
+	case class.IsALU():
+		if op.ALUOp() == Swap && op.Class() == ALU64Class {
+			// B to make BSwap, unconditional byte swap
+			f.WriteString("B")
+		}
+
+		f.WriteString(op.ALUOp().String())
+
+		if op.ALUOp() == Swap {
+			if op.Class() == ALUClass {
+				// Width for Endian is controlled by Constant
+				f.WriteString(op.Endianness().String())
+			}
+		} else {
+			f.WriteString(strings.TrimSuffix(op.Source().String(), "Source"))
+
+			if class == ALUClass {
+				f.WriteString("32")
+			} else {
+				f.WriteString("64")
+			}
+		}
+
+	case class.IsJump():
+		f.WriteString(op.JumpOp().String())
+
+		if class == Jump32Class {
+			f.WriteString("32")
+		} else {
+			f.WriteString("64")
+		}
+
+		if jop := op.JumpOp(); jop != Exit && jop != Call && jop != Ja {
+			f.WriteString(strings.TrimSuffix(op.Source().String(), "Source"))
+		}
+
+	default:
+		fmt.Fprintf(&f, "OpCode(%#x)", uint8(op))
+	}
+
+	return f.String()
+}
+
+// valid returns true if all bits in value are covered by mask.
+func valid(value, mask OpCode) bool {
+	return value & ^mask == 0
+}
+
+// KernelMnemonic renders the OpCode in the kernel's BPF_* macro style,
+// e.g. "BPF_ALU64 | BPF_ADD | BPF_X". This matches the spelling used by
+// verifier logs and kernel documentation, making it easier to cross-reference
+// those with a disassembly. Opcodes which cannot be decomposed fall back to
+// the String representation.
+func (op OpCode) KernelMnemonic() string {
+	class := op.Class()
+	classMacro := class.kernelMacro()
+	if classMacro == "" {
+		return op.String()
+	}
+
+	switch {
+	case class.isLoadOrStore():
+		mode := op.Mode().kernelMacro()
+		size := op.Size().kernelMacro()
+		if mode == "" || size == "" {
+			return op.String()
+		}
+		return classMacro + " | " + mode + " | " + size
+
+	case class.IsALU():
+		aluOp := op.ALUOp()
+		if aluOp == Swap {
+			macro := classMacro + " | BPF_END"
+			if class == ALUClass {
+				if op.Endianness() == BE {
+					return macro + " | BPF_TO_BE"
+				}
+				return macro + " | BPF_TO_LE"
+			}
+			return macro
+		}
+
+		opMacro := aluOp.kernelMacro()
+		source := op.Source().kernelMacro()
+		if opMacro == "" || source == "" {
+			return op.String()
+		}
+		return classMacro + " | " + opMacro + " | " + source
+
+	case class.IsJump():
+		jumpOp := op.JumpOp()
+		opMacro := jumpOp.kernelMacro()
+		if opMacro == "" {
+			return op.String()
+		}
+		if jumpOp == Exit || jumpOp == Call || jumpOp == Ja {
+			return classMacro + " | " + opMacro
+		}
+		source := op.Source().kernelMacro()
+		if source == "" {
+			return op.String()
+		}
+		return classMacro + " | " + opMacro + " | " + source
+
+	default:
+		return op.String()
+	}
+}
+
+func (cls Class) kernelMacro() string {
+	switch cls {
+	case LdClass:
+		return "BPF_LD"
+	case LdXClass:
+		return "BPF_LDX"
+	case StClass:
+		return "BPF_ST"
+	case StXClass:
+		return "BPF_STX"
+	case ALUClass:
+		return "BPF_ALU"
+	case JumpClass:
+		return "BPF_JMP"
+	case Jump32Class:
+		return "BPF_JMP32"
+	case ALU64Class:
+		return "BPF_ALU64"
+	default:
+		return ""
+	}
+}
+
+func (mode Mode) kernelMacro() string {
+	switch mode {
+	case ImmMode:
+		return "BPF_IMM"
+	case AbsMode:
+		return "BPF_ABS"
+	case IndMode:
+		return "BPF_IND"
+	case MemMode:
+		return "BPF_MEM"
+	case MemSXMode:
+		return "BPF_MEMSX"
+	case AtomicMode:
+		return "BPF_ATOMIC"
+	default:
+		return ""
+	}
+}
+
+func (size Size) kernelMacro() string {
+	switch size {
+	case Word:
+		return "BPF_W"
+	case Half:
+		return "BPF_H"
+	case Byte:
+		return "BPF_B"
+	case DWord:
+		return "BPF_DW"
+	default:
+		return ""
+	}
+}
+
+func (source Source) kernelMacro() string {
+	switch source {
+	case ImmSource:
+		return "BPF_K"
+	case RegSource:
+		return "BPF_X"
+	default:
+		return ""
+	}
+}
+
+func (op ALUOp) kernelMacro() string {
+	switch op {
+	case Add:
+		return "BPF_ADD"
+	case Sub:
+		return "BPF_SUB"
+	case Mul:
+		return "BPF_MUL"
+	case Div, SDiv:
+		return "BPF_DIV"
+	case Or:
+		return "BPF_OR"
+	case And:
+		return "BPF_AND"
+	case LSh:
+		return "BPF_LSH"
+	case RSh:
+		return "BPF_RSH"
+	case Neg:
+		return "BPF_NEG"
+	case Mod, SMod:
+		return "BPF_MOD"
+	case Xor:
+		return "BPF_XOR"
+	case Mov, MovSX8, MovSX16, MovSX32:
+		return "BPF_MOV"
+	case ArSh:
+		return "BPF_ARSH"
+	default:
+		return ""
+	}
+}
+
+func (op JumpOp) kernelMacro() string {
+	switch op {
+	case Ja:
+		return "BPF_JA"
+	case JEq:
+		return "BPF_JEQ"
+	case JGT:
+		return "BPF_JGT"
+	case JGE:
+		return "BPF_JGE"
+	case JSet:
+		return "BPF_JSET"
+	case JNE:
+		return "BPF_JNE"
+	case JSGT:
+		return "BPF_JSGT"
+	case JSGE:
+		return "BPF_JSGE"
+	case Call:
+		return "BPF_CALL"
+	case Exit:
+		return "BPF_EXIT"
+	case JLT:
+		return "BPF_JLT"
+	case JLE:
+		return "BPF_JLE"
+	case JSLT:
+		return "BPF_JSLT"
+	case JSLE:
+		return "BPF_JSLE"
+	default:
+		return ""
+	}
+}
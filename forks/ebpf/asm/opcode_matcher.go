@@ -0,0 +1,51 @@
+package asm
+
+// OpCodeMatcher is a predicate over an OpCode, used to express opcode
+// scans and rewrite passes declaratively.
+type OpCodeMatcher func(OpCode) bool
+
+// MatchClass matches opcodes of the given class.
+func MatchClass(cls Class) OpCodeMatcher {
+	return func(op OpCode) bool {
+		return op.Class() == cls
+	}
+}
+
+// MatchJumpOp matches opcodes encoding the given jump operation.
+func MatchJumpOp(jump JumpOp) OpCodeMatcher {
+	return func(op OpCode) bool {
+		return op.JumpOp() == jump
+	}
+}
+
+// MatchALUOp matches opcodes encoding the given ALU operation.
+func MatchALUOp(alu ALUOp) OpCodeMatcher {
+	return func(op OpCode) bool {
+		return op.ALUOp() == alu
+	}
+}
+
+// And matches opcodes matched by both m and other.
+func (m OpCodeMatcher) And(other OpCodeMatcher) OpCodeMatcher {
+	return func(op OpCode) bool {
+		return m(op) && other(op)
+	}
+}
+
+// Or matches opcodes matched by either m or other.
+func (m OpCodeMatcher) Or(other OpCodeMatcher) OpCodeMatcher {
+	return func(op OpCode) bool {
+		return m(op) || other(op)
+	}
+}
+
+// FindOpCodes returns the indices of all opcodes in ops matched by m.
+func FindOpCodes(ops []OpCode, m OpCodeMatcher) []int {
+	var indices []int
+	for i, op := range ops {
+		if m(op) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
@@ -0,0 +1,39 @@
+package asm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindOpCodes(t *testing.T) {
+	// A small sample program: load, arithmetic of both widths, a branch and
+	// the usual epilogue.
+	program := []OpCode{
+		LoadImmOp(DWord),                  // 0
+		Mov.Op(RegSource),                 // 1
+		Add.Op(ImmSource),                 // 2
+		Add.Op32(ImmSource),               // 3
+		JEq.Op(ImmSource),                 // 4
+		Mov.Op32(ImmSource),               // 5
+		OpCode(JumpClass).SetJumpOp(Exit), // 6
+	}
+
+	testcases := []struct {
+		name    string
+		matcher OpCodeMatcher
+		want    []int
+	}{
+		{"class", MatchClass(ALU64Class), []int{1, 2}},
+		{"jump op", MatchJumpOp(JEq), []int{4}},
+		{"alu op", MatchALUOp(Add), []int{2, 3}},
+		{"and", MatchALUOp(Add).And(MatchClass(ALUClass)), []int{3}},
+		{"or", MatchJumpOp(Exit).Or(MatchALUOp(Mov)), []int{1, 5, 6}},
+		{"no match", MatchClass(StXClass), nil},
+	}
+
+	for _, tc := range testcases {
+		if have := FindOpCodes(program, tc.matcher); !slices.Equal(have, tc.want) {
+			t.Errorf("%s: FindOpCodes() = %v, want %v", tc.name, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package asm
+
+import "fmt"
+
+// OpCodeReader iterates over the opcodes of an encoded program without
+// decoding full instructions or allocating. It advances by the correct
+// number of instruction slots, so the second slot of an imm64 load is
+// never misinterpreted as an opcode.
+//
+// Only the raw opcode byte is recovered: the extended atomic operation
+// bits, which live in the constant field of the encoded instruction, are
+// not populated.
+type OpCodeReader struct {
+	buf []byte
+	off int
+	err error
+}
+
+// NewOpCodeReader returns a reader over the encoded program in buf.
+func NewOpCodeReader(buf []byte) *OpCodeReader {
+	return &OpCodeReader{buf: buf}
+}
+
+// Next returns the next opcode in the program. It returns false when the
+// program is exhausted or malformed; the two cases are distinguished by Err.
+func (r *OpCodeReader) Next() (OpCode, bool) {
+	if r.err != nil || r.off >= len(r.buf) {
+		return InvalidOpCode, false
+	}
+
+	if rest := len(r.buf) - r.off; rest < InstructionSize {
+		r.err = fmt.Errorf("truncated instruction at offset %d: %d bytes left", r.off, rest)
+		return InvalidOpCode, false
+	}
+
+	op := OpCode(r.buf[r.off])
+	size := op.RawByteLen()
+	if rest := len(r.buf) - r.off; rest < size {
+		r.err = fmt.Errorf("truncated %v at offset %d: need %d bytes, have %d", op, r.off, size, rest)
+		return InvalidOpCode, false
+	}
+
+	r.off += size
+	return op, true
+}
+
+// Remaining returns the number of unread bytes.
+func (r *OpCodeReader) Remaining() int {
+	return len(r.buf) - r.off
+}
+
+// Err returns the error that caused Next to return false, if any. Running
+// off the end of a well-formed program is not an error.
+func (r *OpCodeReader) Err() error {
+	return r.err
+}
@@ -0,0 +1,70 @@
+package asm
+
+import "testing"
+
+// encodeOpCodes builds a minimal encoded program holding the given opcodes,
+// with zeroed registers, offsets and constants.
+func encodeOpCodes(ops ...OpCode) []byte {
+	var buf []byte
+	for _, op := range ops {
+		ins := make([]byte, op.RawByteLen())
+		ins[0] = byte(op)
+		buf = append(buf, ins...)
+	}
+	return buf
+}
+
+func TestOpCodeReader(t *testing.T) {
+	program := []OpCode{
+		LoadImmOp(DWord),
+		Mov.Op(RegSource),
+		OpCode(JumpClass).SetJumpOp(Exit),
+	}
+
+	r := NewOpCodeReader(encodeOpCodes(program...))
+	for i, want := range program {
+		op, ok := r.Next()
+		if !ok {
+			t.Fatalf("Next() = false at opcode %d, err: %v", i, r.Err())
+		}
+		if op != want {
+			t.Fatalf("opcode %d: got %v, want %v", i, op, want)
+		}
+	}
+
+	if op, ok := r.Next(); ok {
+		t.Fatalf("Next() = %v after the last opcode, want exhaustion", op)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("exhausting a well-formed program is not an error, got: %s", err)
+	}
+	if r.Remaining() != 0 {
+		t.Fatalf("Remaining() = %d after a full read", r.Remaining())
+	}
+}
+
+func TestOpCodeReaderTruncated(t *testing.T) {
+	// A valid instruction followed by half of another.
+	buf := encodeOpCodes(Add.Op(ImmSource))
+	buf = append(buf, make([]byte, InstructionSize/2)...)
+
+	r := NewOpCodeReader(buf)
+	if _, ok := r.Next(); !ok {
+		t.Fatalf("reading the leading instruction failed: %v", r.Err())
+	}
+	if op, ok := r.Next(); ok {
+		t.Fatalf("Next() = %v on a truncated tail, want failure", op)
+	}
+	if r.Err() == nil {
+		t.Fatal("a truncated tail must surface through Err")
+	}
+
+	// An imm64 load missing its second slot.
+	r = NewOpCodeReader(append([]byte{byte(LoadImmOp(DWord))}, make([]byte, InstructionSize-1)...))
+	if op, ok := r.Next(); ok {
+		t.Fatalf("Next() = %v on a truncated imm64 load, want failure", op)
+	}
+	if r.Err() == nil {
+		t.Fatal("a truncated imm64 load must surface through Err")
+	}
+}
@@ -0,0 +1,432 @@
+package asm
+
+import "testing"
+
+func TestWithClass(t *testing.T) {
+	valid := []struct {
+		op   OpCode
+		cls  Class
+		want OpCode
+	}{
+		// Converting between the ALU classes preserves the operation.
+		{Add.Op32(ImmSource), ALU64Class, Add.Op(ImmSource)},
+		{Mov.Op(RegSource), ALUClass, Mov.Op32(RegSource)},
+		// Same for the jump classes.
+		{JEq.Op(RegSource), Jump32Class, JEq.Op(RegSource).SetSource(RegSource) & ^classMask | OpCode(Jump32Class)},
+		// Regular memory accesses exist in all four load/store classes.
+		{LoadMemOp(Word), LdClass, LoadMemOp(Word) & ^classMask | OpCode(LdClass)},
+		{StoreMemOp(Word), StClass, StoreImmOp(Word)},
+		// Converting to the same class is a no-op.
+		{Add.Op(ImmSource), ALU64Class, Add.Op(ImmSource)},
+	}
+
+	for _, tc := range valid {
+		have, err := tc.op.WithClass(tc.cls)
+		if err != nil {
+			t.Errorf("%v.WithClass(%v): unexpected error: %s", tc.op, tc.cls, err)
+			continue
+		}
+		if have != tc.want {
+			t.Errorf("%v.WithClass(%v) = %v, want %v", tc.op, tc.cls, have, tc.want)
+		}
+		if have.Class() != tc.cls {
+			t.Errorf("%v.WithClass(%v) has class %v", tc.op, tc.cls, have.Class())
+		}
+	}
+
+	invalid := []struct {
+		op  OpCode
+		cls Class
+	}{
+		// Incompatible kinds of classes.
+		{LoadImmOp(DWord), JumpClass},
+		{Add.Op(ImmSource), LdClass},
+		{JEq.Op(ImmSource), ALUClass},
+		{LoadMemOp(Word), StClass},
+		// Exit and Call only exist in JumpClass.
+		{OpCode(JumpClass).SetJumpOp(Exit), Jump32Class},
+		{OpCode(JumpClass).SetJumpOp(Call), Jump32Class},
+		// An immediate load is only encodable in LdClass.
+		{LoadImmOp(DWord), LdXClass},
+		// Sign-extending loads are register loads.
+		{LoadMemSXOp(Word), LdClass},
+		// Atomic operations require StXClass.
+		{StoreXAddOp(Word), StClass},
+		// Swap changes semantics between the ALU classes.
+		{HostTo(BE, R0, Half).OpCode, ALU64Class},
+		{BSwap(R0, Word).OpCode, ALUClass},
+	}
+
+	for _, tc := range invalid {
+		if have, err := tc.op.WithClass(tc.cls); err == nil {
+			t.Errorf("%v.WithClass(%v) = %v, want an error", tc.op, tc.cls, have)
+		}
+	}
+}
+
+func TestKernelMnemonic(t *testing.T) {
+	testcases := []struct {
+		op   OpCode
+		want string
+	}{
+		{Add.Op(ImmSource), "BPF_ALU64 | BPF_ADD | BPF_K"},
+		{Mov.Op32(RegSource), "BPF_ALU | BPF_MOV | BPF_X"},
+		{JEq.Op(RegSource), "BPF_JMP | BPF_JEQ | BPF_X"},
+		{OpCode(Jump32Class).SetJumpOp(JSLT).SetSource(ImmSource), "BPF_JMP32 | BPF_JSLT | BPF_K"},
+		{OpCode(JumpClass).SetJumpOp(Exit), "BPF_JMP | BPF_EXIT"},
+		{OpCode(JumpClass).SetJumpOp(Call), "BPF_JMP | BPF_CALL"},
+		{LoadMemOp(Word), "BPF_LDX | BPF_MEM | BPF_W"},
+		{LoadImmOp(DWord), "BPF_LD | BPF_IMM | BPF_DW"},
+		{StoreMemOp(Half), "BPF_STX | BPF_MEM | BPF_H"},
+		{HostTo(BE, R0, Half).OpCode, "BPF_ALU | BPF_END | BPF_TO_BE"},
+		{BSwap(R0, Word).OpCode, "BPF_ALU64 | BPF_END"},
+	}
+
+	for _, tc := range testcases {
+		if have := tc.op.KernelMnemonic(); have != tc.want {
+			t.Errorf("%v.KernelMnemonic() = %q, want %q", tc.op, have, tc.want)
+		}
+	}
+}
+
+func TestRawByteLen(t *testing.T) {
+	// A 64-bit immediate load occupies two instruction slots.
+	if have := LoadImmOp(DWord).RawByteLen(); have != 2*InstructionSize {
+		t.Errorf("LoadImmOp(DWord).RawByteLen() = %d, want %d", have, 2*InstructionSize)
+	}
+
+	// Everything else encodes in a single slot.
+	singles := []OpCode{
+		LoadMemOp(DWord),
+		LoadImmOp(Word),
+		Add.Op(ImmSource),
+		JEq.Op(RegSource),
+		OpCode(JumpClass).SetJumpOp(Exit),
+	}
+	for _, op := range singles {
+		if have := op.RawByteLen(); have != InstructionSize {
+			t.Errorf("%v.RawByteLen() = %d, want %d", op, have, InstructionSize)
+		}
+	}
+}
+
+func TestEqualIgnoringSource(t *testing.T) {
+	testcases := []struct {
+		a, b OpCode
+		want bool
+	}{
+		// reg/imm variants of the same operation compare equal.
+		{Add.Op(RegSource), Add.Op(ImmSource), true},
+		{Add.Op32(RegSource), Add.Op32(ImmSource), true},
+		{JEq.Op(RegSource), JEq.Op(ImmSource), true},
+		{Mov.Op(ImmSource), Mov.Op(ImmSource), true},
+		// Different operations or widths do not.
+		{Add.Op(RegSource), Sub.Op(RegSource), false},
+		{Add.Op(ImmSource), Add.Op32(ImmSource), false},
+		{JEq.Op(ImmSource), JNE.Op(ImmSource), false},
+		// Swap's source bit is the target byte order, compared exactly.
+		{HostTo(BE, R0, Half).OpCode, HostTo(LE, R0, Half).OpCode, false},
+		{HostTo(BE, R0, Half).OpCode, HostTo(BE, R0, Half).OpCode, true},
+		// Other classes fall back to exact comparison.
+		{LoadMemOp(Word), LoadMemOp(Word), true},
+		{LoadMemOp(Word), LoadMemOp(DWord), false},
+	}
+
+	for _, tc := range testcases {
+		if have := tc.a.EqualIgnoringSource(tc.b); have != tc.want {
+			t.Errorf("%v.EqualIgnoringSource(%v) = %t, want %t", tc.a, tc.b, have, tc.want)
+		}
+		// The relation is symmetric.
+		if have := tc.b.EqualIgnoringSource(tc.a); have != tc.want {
+			t.Errorf("%v.EqualIgnoringSource(%v) = %t, want %t", tc.b, tc.a, have, tc.want)
+		}
+	}
+}
+
+func TestIsImm64Load(t *testing.T) {
+	if op := LoadImmOp(DWord); !op.IsImm64Load() {
+		t.Errorf("%v.IsImm64Load() = false, want true", op)
+	}
+
+	notImm64 := []OpCode{
+		// A DWord load from memory is a single-slot instruction.
+		LoadMemOp(DWord),
+		// Smaller immediate loads don't spill into a second slot either.
+		LoadImmOp(Word),
+		StoreMemOp(DWord),
+		Add.Op(ImmSource),
+	}
+	for _, op := range notImm64 {
+		if op.IsImm64Load() {
+			t.Errorf("%v.IsImm64Load() = true, want false", op)
+		}
+	}
+}
+
+func TestStringVerbose(t *testing.T) {
+	testcases := []struct {
+		op   OpCode
+		want string
+	}{
+		// ALU operations always carry an explicit width suffix.
+		{Add.Op(ImmSource), "AddImm64"},
+		{Add.Op32(ImmSource), "AddImm32"},
+		{Mov.Op(RegSource), "MovReg64"},
+		// So do jumps.
+		{JEq.Op(RegSource), "JEq64Reg"},
+		{OpCode(Jump32Class).SetJumpOp(JEq).SetSource(RegSource), "JEq32Reg"},
+		{OpCode(JumpClass).SetJumpOp(Exit), "Exit64"},
+		// Load and store sizes are spelled out in bits.
+		{LoadMemOp(Word), "LdXMem32"},
+		{LoadMemOp(DWord), "LdXMem64"},
+		{LoadMemOp(Byte), "LdXMem8"},
+		{LoadImmOp(DWord), "LdImm64"},
+		{StoreMemOp(Half), "StXMem16"},
+		// Byte swaps are unambiguous already and keep their spelling.
+		{HostTo(BE, R0, Half).OpCode, "SwapBE"},
+		{BSwap(R0, Word).OpCode, "BSwap"},
+	}
+
+	for _, tc := range testcases {
+		if have := tc.op.StringVerbose(); have != tc.want {
+			t.Errorf("%v.StringVerbose() = %q, want %q", tc.op, have, tc.want)
+		}
+	}
+}
+
+func TestIsCall(t *testing.T) {
+	if op := OpCode(JumpClass).SetJumpOp(Call); !op.IsCall() {
+		t.Errorf("%v.IsCall() = false, want true", op)
+	}
+
+	notCalls := []OpCode{
+		// Call only exists in JumpClass; the same bit pattern in Jump32Class
+		// is not a valid jump op at all.
+		OpCode(Jump32Class) | (OpCode(JumpClass).SetJumpOp(Call) & ^classMask),
+		OpCode(JumpClass).SetJumpOp(Exit),
+		OpCode(JumpClass).SetJumpOp(Ja),
+		JEq.Op(ImmSource),
+		// Non-jump classes never encode a call.
+		Add.Op(ImmSource),
+		LoadMemOp(Word),
+	}
+	for _, op := range notCalls {
+		if op.IsCall() {
+			t.Errorf("%v.IsCall() = true, want false", op)
+		}
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	valid := []struct {
+		op     OpCode
+		source Source
+		want   OpCode
+	}{
+		// reg↔imm for ALU operations of both widths.
+		{Add.Op(RegSource), ImmSource, Add.Op(ImmSource)},
+		{Add.Op(ImmSource), RegSource, Add.Op(RegSource)},
+		{Mov.Op32(RegSource), ImmSource, Mov.Op32(ImmSource)},
+		// reg↔imm for jump operations.
+		{JEq.Op(RegSource), ImmSource, JEq.Op(ImmSource)},
+		{JGT.Op(ImmSource), RegSource, JGT.Op(RegSource)},
+		// Setting the current source is a no-op.
+		{Sub.Op(ImmSource), ImmSource, Sub.Op(ImmSource)},
+	}
+
+	for _, tc := range valid {
+		have, err := tc.op.WithSource(tc.source)
+		if err != nil {
+			t.Errorf("%v.WithSource(%v): unexpected error: %s", tc.op, tc.source, err)
+			continue
+		}
+		if have != tc.want {
+			t.Errorf("%v.WithSource(%v) = %v, want %v", tc.op, tc.source, have, tc.want)
+		}
+	}
+
+	invalid := []OpCode{
+		// Swap has no operand-kind source bit.
+		HostTo(BE, R0, Half).OpCode,
+		BSwap(R0, Word).OpCode,
+		// Operand-less jump operations.
+		OpCode(JumpClass).SetJumpOp(Exit),
+		OpCode(JumpClass).SetJumpOp(Call),
+		OpCode(JumpClass).SetJumpOp(Ja),
+		// Wrong class entirely.
+		LoadMemOp(Word),
+	}
+
+	for _, op := range invalid {
+		if have, err := op.WithSource(RegSource); err == nil {
+			t.Errorf("%v.WithSource(RegSource) = %v, want an error", op, have)
+		}
+	}
+}
+
+func TestNegateJump(t *testing.T) {
+	complements := map[JumpOp]JumpOp{
+		JEq:  JNE,
+		JNE:  JEq,
+		JGT:  JLE,
+		JLE:  JGT,
+		JGE:  JLT,
+		JLT:  JGE,
+		JSGT: JSLE,
+		JSLE: JSGT,
+		JSGE: JSLT,
+		JSLT: JSGE,
+	}
+
+	for _, cls := range []Class{JumpClass, Jump32Class} {
+		for jop, want := range complements {
+			op := OpCode(cls).SetJumpOp(jop).SetSource(RegSource)
+			have, err := op.NegateJump()
+			if err != nil {
+				t.Errorf("%v.NegateJump(): unexpected error: %s", op, err)
+				continue
+			}
+			if have.JumpOp() != want {
+				t.Errorf("%v.NegateJump() = %v, want jump op %v", op, have, want)
+			}
+			// Class and source are preserved.
+			if have.Class() != cls || have.Source() != RegSource {
+				t.Errorf("%v.NegateJump() = %v, class or source not preserved", op, have)
+			}
+			// Negation is an involution.
+			if back, err := have.NegateJump(); err != nil || back != op {
+				t.Errorf("%v.NegateJump() = %v, want %v (err: %v)", have, back, op, err)
+			}
+		}
+	}
+
+	rejected := []OpCode{
+		// JSet's complement has no encoding.
+		JSet.Op(ImmSource),
+		// Unconditional jumps cannot be negated.
+		OpCode(JumpClass).SetJumpOp(Ja),
+		OpCode(JumpClass).SetJumpOp(Call),
+		OpCode(JumpClass).SetJumpOp(Exit),
+		// Neither can non-jumps.
+		Add.Op(ImmSource),
+	}
+	for _, op := range rejected {
+		if have, err := op.NegateJump(); err == nil {
+			t.Errorf("%v.NegateJump() = %v, want an error", op, have)
+		}
+	}
+}
+
+func TestIsSignedJump(t *testing.T) {
+	signed := []JumpOp{JSGT, JSGE, JSLT, JSLE}
+	unsigned := []JumpOp{JEq, JNE, JGT, JGE, JLT, JLE, JSet}
+
+	for _, cls := range []Class{JumpClass, Jump32Class} {
+		for _, jop := range signed {
+			if op := OpCode(cls).SetJumpOp(jop).SetSource(ImmSource); !op.IsSignedJump() {
+				t.Errorf("%v.IsSignedJump() = false, want true", op)
+			}
+		}
+		for _, jop := range unsigned {
+			if op := OpCode(cls).SetJumpOp(jop).SetSource(ImmSource); op.IsSignedJump() {
+				t.Errorf("%v.IsSignedJump() = true, want false", op)
+			}
+		}
+	}
+
+	// Unconditional jumps and other classes are never signed comparisons.
+	for _, op := range []OpCode{
+		OpCode(JumpClass).SetJumpOp(Exit),
+		OpCode(JumpClass).SetJumpOp(Ja),
+		Add.Op(ImmSource),
+	} {
+		if op.IsSignedJump() {
+			t.Errorf("%v.IsSignedJump() = true, want false", op)
+		}
+	}
+}
+
+func TestAtomicPredicates(t *testing.T) {
+	type preds struct {
+		add, and, or, xor, xchg, cmpXchg bool
+	}
+	testcases := []struct {
+		op   OpCode
+		want preds
+	}{
+		// Each predicate covers the plain and the fetch variant.
+		{AddAtomic.OpCode(Word), preds{add: true}},
+		{FetchAdd.OpCode(DWord), preds{add: true}},
+		{AndAtomic.OpCode(Word), preds{and: true}},
+		{FetchAnd.OpCode(Word), preds{and: true}},
+		{OrAtomic.OpCode(Word), preds{or: true}},
+		{FetchOr.OpCode(Word), preds{or: true}},
+		{XorAtomic.OpCode(Word), preds{xor: true}},
+		{FetchXor.OpCode(Word), preds{xor: true}},
+		{Xchg.OpCode(Word), preds{xchg: true}},
+		{CmpXchg.OpCode(Word), preds{cmpXchg: true}},
+		// Non-atomic opcodes match none.
+		{StoreMemOp(Word), preds{}},
+		{Add.Op(ImmSource), preds{}},
+	}
+
+	for _, tc := range testcases {
+		have := preds{
+			add:     tc.op.IsAtomicAdd(),
+			and:     tc.op.IsAtomicAnd(),
+			or:      tc.op.IsAtomicOr(),
+			xor:     tc.op.IsAtomicXor(),
+			xchg:    tc.op.IsAtomicXchg(),
+			cmpXchg: tc.op.IsAtomicCmpXchg(),
+		}
+		if have != tc.want {
+			t.Errorf("%v: atomic predicates = %+v, want %+v", tc.op, have, tc.want)
+		}
+	}
+}
+
+func TestIsSignExtendingLoad(t *testing.T) {
+	for _, size := range []Size{Byte, Half, Word} {
+		if op := LoadMemSXOp(size); !op.IsSignExtendingLoad() {
+			t.Errorf("%v.IsSignExtendingLoad() = false, want true", op)
+		}
+	}
+
+	regular := []OpCode{
+		LoadMemOp(Word),
+		LoadMemOp(DWord),
+		LoadImmOp(DWord),
+		StoreMemOp(Word),
+		Add.Op(ImmSource),
+	}
+	for _, op := range regular {
+		if op.IsSignExtendingLoad() {
+			t.Errorf("%v.IsSignExtendingLoad() = true, want false", op)
+		}
+	}
+}
+
+func TestMinKernelVersion(t *testing.T) {
+	testcases := []struct {
+		op   OpCode
+		want string
+	}{
+		// Baseline opcodes carry no version requirement.
+		{Add.Op(ImmSource), ""},
+		{LoadMemOp(Word), ""},
+		{JEq.Op(RegSource), ""},
+		{StoreXAddOp(Word), ""},
+		// 32-bit jumps.
+		{OpCode(Jump32Class).SetJumpOp(JEq).SetSource(RegSource), "5.1"},
+		// BPF_ATOMIC operations other than the legacy exchange-and-add.
+		{FetchAnd.OpCode(Word), "5.12"},
+		// Sign-extending loads are cpu v4.
+		{LoadMemSXOp(Word), "6.4"},
+	}
+
+	for _, tc := range testcases {
+		if have := tc.op.MinKernelVersion(); have != tc.want {
+			t.Errorf("%v.MinKernelVersion() = %q, want %q", tc.op, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package test checks that the code generated by bpf2go conforms to a
+// specific API.
+package test
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -tags linux test ../testdata/minimal.c
@@ -0,0 +1,69 @@
+//go:build linux
+
+// getting_started_main {
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/rlimit"
+)
+
+func main() {
+	// Remove resource limits for kernels <5.11.
+	if err := rlimit.RemoveMemlock(); err != nil { // (1)!
+		log.Fatal("Removing memlock:", err)
+	}
+
+	// Load the compiled eBPF ELF and load it into the kernel.
+	var objs counterObjects // (2)!
+	if err := loadCounterObjects(&objs, nil); err != nil {
+		log.Fatal("Loading eBPF objects:", err)
+	}
+	defer objs.Close() // (3)!
+
+	ifname := "eth0" // Change this to an interface on your machine.
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		log.Fatalf("Getting interface %s: %s", ifname, err)
+	}
+
+	// Attach count_packets to the network interface.
+	link, err := link.AttachXDP(link.XDPOptions{ // (4)!
+		Program:   objs.CountPackets,
+		Interface: iface.Index,
+	})
+	if err != nil {
+		log.Fatal("Attaching XDP:", err)
+	}
+	defer link.Close() // (5)!
+
+	log.Printf("Counting incoming packets on %s..", ifname)
+
+	// Periodically fetch the packet counter from PktCount,
+	// exit the program when interrupted.
+	tick := time.Tick(time.Second)
+	stop := make(chan os.Signal, 5)
+	signal.Notify(stop, os.Interrupt)
+	for {
+		select {
+		case <-tick:
+			var count uint64
+			err := objs.PktCount.Lookup(uint32(0), &count) // (6)!
+			if err != nil {
+				log.Fatal("Map lookup:", err)
+			}
+			log.Printf("Received %d packets", count)
+		case <-stop:
+			log.Print("Received signal, exiting..")
+			return
+		}
+	}
+}
+
+// }
@@ -0,0 +1,27 @@
+package features
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/internal"
+	"github.com/cilium/ebpf/internal/testutils"
+)
+
+func TestHaveMapType(t *testing.T) {
+	testutils.CheckFeatureMatrix(t, haveMapTypeMatrix)
+}
+
+func TestHaveMapFlag(t *testing.T) {
+	testutils.CheckFeatureMatrix(t, haveMapFlagsMatrix)
+}
+
+func TestHaveMapTypeInvalid(t *testing.T) {
+	if err := HaveMapType(ebpf.MapType(math.MaxUint32)); err == nil {
+		t.Fatal("Expected an error")
+	} else if errors.Is(err, internal.ErrNotSupported) {
+		t.Fatal("Got ErrNotSupported:", err)
+	}
+}
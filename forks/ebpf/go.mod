@@ -0,0 +1,23 @@
+module github.com/cilium/ebpf
+
+go 1.23.0
+
+toolchain go1.24.1
+
+require (
+	github.com/go-quicktest/qt v1.101.1-0.20240301121107-c6c8733fa1e6
+	github.com/google/go-cmp v0.6.0
+	github.com/jsimonetti/rtnetlink/v2 v2.0.1
+	golang.org/x/sys v0.31.0
+)
+
+require (
+	github.com/josharian/native v1.1.0 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/mdlayher/netlink v1.7.2 // indirect
+	github.com/mdlayher/socket v0.4.1 // indirect
+	github.com/rogpeppe/go-internal v1.12.0 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+)
@@ -0,0 +1,5 @@
+// Package perf allows reading from BPF perf event arrays.
+//
+// A perf event array contains multiple perf event ringbuffers which can be used
+// to exchange sample like data with user space.
+package perf
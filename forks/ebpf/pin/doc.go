@@ -0,0 +1,3 @@
+// Package pin provides utility functions for working with pinned objects on bpffs.
+
+package pin
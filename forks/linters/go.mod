@@ -0,0 +1,11 @@
+module github.com/cilium/linters
+
+go 1.23.0
+
+require (
+	golang.org/x/exp v0.0.0-20230809094429-853ea248256d
+	golang.org/x/mod v0.24.0
+	golang.org/x/tools v0.32.0
+)
+
+require golang.org/x/sync v0.13.0 // indirect
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package main creates the main function to run all analyzers in this module.
+package main
+
+import (
+	"github.com/cilium/linters/ioreadall"
+	"github.com/cilium/linters/slowg"
+	"github.com/cilium/linters/timeafter"
+
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(
+		ioreadall.Analyzer,
+		slowg.Analyzer,
+		timeafter.Analyzer,
+	)
+}
@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package fixture is ignored by import path, so the time.After use below
+// must not be reported.
+package fixture
+
+import "time"
+
+func loop(ch chan struct{}) {
+	for range ch {
+		<-time.After(time.Second)
+	}
+}
@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package inctimer is a stand-in for the real package of the same import
+// path, letting fixtures control whether it is importable.
+package inctimer
+
+import "time"
+
+func After(d time.Duration) <-chan time.Time {
+	timer := time.NewTimer(d)
+	return timer.C
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package newtimer
+
+import "time"
+
+func stopped(ch chan struct{}) {
+	for range ch {
+		t := time.NewTimer(time.Second)
+		<-t.C
+		t.Stop()
+	}
+}
+
+func unstopped(ch chan struct{}) {
+	for range ch {
+		t := time.NewTimer(time.Second) // want `use of time.NewTimer in a for loop without stopping the returned timer is prohibited`
+		<-t.C
+	}
+}
@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+// Package fixture shares its name with the ignored package but lives under
+// a different import path, so it is still checked.
+package fixture
+
+import "time"
+
+func loop(ch chan struct{}) {
+	for range ch {
+		<-time.After(time.Second) // want `use of time.After in a for loop is prohibited, use inctimer instead`
+	}
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package timeafterarg
+
+import "time"
+
+func foo(ch <-chan time.Time) {}
+
+// time.After passed as an argument to another call is still evaluated once
+// per loop iteration and leaks a timer the same way.
+func loop(ch chan struct{}) {
+	for range ch {
+		foo(time.After(time.Second)) // want `use of time.After in a for loop is prohibited, use inctimer instead`
+	}
+}
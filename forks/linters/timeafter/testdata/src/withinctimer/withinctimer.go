@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package withinctimer
+
+import (
+	"time"
+
+	"github.com/cilium/cilium/pkg/inctimer"
+)
+
+var _ = inctimer.After
+
+func loop(ch chan struct{}) {
+	for range ch {
+		<-time.After(time.Second) // want `use of time.After in a for loop is prohibited, use inctimer instead`
+	}
+}
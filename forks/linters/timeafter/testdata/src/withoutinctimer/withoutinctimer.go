@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package withoutinctimer
+
+import "time"
+
+func loop(ch chan struct{}) {
+	for range ch {
+		<-time.After(time.Second) // want `use of time.After in a for loop is prohibited, use inctimer instead`
+	}
+}
@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package timeafter
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/cilium/linters/analysisutil"
+)
+
+const (
+	timeAfterPkg     = "time"
+	timeAfterFunc    = "After"
+	timeNewTimerFunc = "NewTimer"
+	timerStopMethod  = "Stop"
+
+	// inctimerPkgPath is the package whose After is suggested as the
+	// replacement for time.After in loops.
+	inctimerPkgPath = "github.com/cilium/cilium/pkg/inctimer"
+)
+
+// Analyzer implements an analysis function that checks for the use of
+// time.After in loops.
+var Analyzer = &analysis.Analyzer{
+	Name:     "timeafter",
+	Doc:      `check for "time.After" instances in loops`,
+	URL:      "https://github.com/cilium/linters",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	ignoreArg      string
+	ignorePathsArg string
+	checkNewTimer  bool
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&ignoreArg, "ignore", "", `list of packages to ignore ("inctimer,time")`)
+	Analyzer.Flags.StringVar(&ignorePathsArg, "ignore-paths", "", `list of package import paths to ignore ("github.com/cilium/cilium/pkg/inctimer")`)
+	Analyzer.Flags.BoolVar(&checkNewTimer, "check-newtimer", false, `flag "time.NewTimer" calls in loops whose timer is never Stop()ped in the same block (heuristic)`)
+}
+
+type visitor func(ast.Node) bool
+
+func (v visitor) Visit(node ast.Node) ast.Visitor {
+	if v(node) {
+		return v
+	}
+	return nil
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !analysisutil.ImportsPackage(pass.Pkg, "time") {
+		return nil, nil // doesn't directly import time package
+	}
+
+	// Unlike -ignore, which matches on the (possibly ambiguous) package name,
+	// -ignore-paths matches on the package's full import path.
+	for _, ign := range strings.Split(ignorePathsArg, ",") {
+		if ign != "" && strings.TrimSpace(ign) == pass.Pkg.Path() {
+			return nil, nil
+		}
+	}
+
+	inspct, ok := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	if !ok {
+		return nil, errors.New("analyzer is not type *inspector.Inspector")
+	}
+
+	var goVersion string
+	if pass.Module != nil {
+		goVersion = pass.Module.GoVersion
+		if !strings.HasPrefix(goVersion, "v") {
+			goVersion = "v" + goVersion
+		}
+	}
+	if semver.Compare(goVersion, "v1.23.0") >= 0 {
+		// Go version ≥ 1.23 no longer has the issue of not collecting unstopped Timers and
+		// time.After can safely be used in loops. Also see
+		// https://go.dev/doc/go1.23#timer-changes and
+		// https://cs.opensource.google/go/go/+/refs/tags/go1.23.2:src/time/sleep.go;l=196-201
+		return nil, nil
+	}
+
+	// A rewrite to inctimer.After only compiles in packages that already
+	// import inctimer; elsewhere the diagnostic is reported without a fix.
+	inctimerAvailable := analysisutil.ImportsPackage(pass.Pkg, inctimerPkgPath)
+
+	ignoreMap := make(map[string]struct{})
+	for _, ign := range strings.Split(ignoreArg, ",") {
+		ignoreMap[strings.TrimSpace(ign)] = struct{}{}
+	}
+
+	var (
+		pkgAliases []string
+		ignore     = false
+		nodeFilter = []ast.Node{
+			(*ast.ForStmt)(nil),
+			(*ast.RangeStmt)(nil),
+			(*ast.File)(nil),
+			(*ast.ImportSpec)(nil),
+		}
+	)
+	inspct.Preorder(nodeFilter, func(n ast.Node) {
+		switch stmt := n.(type) {
+		case *ast.File:
+			_, ignore = ignoreMap[stmt.Name.Name]
+			pkgAliases = []string{timeAfterPkg}
+		case *ast.ImportSpec:
+			if ignore {
+				return
+			}
+			// Collect aliases.
+			pkg := stmt.Path.Value
+			if pkg == fmt.Sprintf("%q", timeAfterPkg) {
+				if stmt.Name != nil {
+					pkgAliases = append(pkgAliases, stmt.Name.Name)
+				}
+			}
+		case *ast.ForStmt:
+			if ignore {
+				return
+			}
+			checkForStmt(pass, stmt.Body, pkgAliases, inctimerAvailable)
+			if checkNewTimer {
+				checkNewTimerStmt(pass, stmt.Body, pkgAliases)
+			}
+		case *ast.RangeStmt:
+			if ignore {
+				return
+			}
+			checkForStmt(pass, stmt.Body, pkgAliases, inctimerAvailable)
+			if checkNewTimer {
+				checkNewTimerStmt(pass, stmt.Body, pkgAliases)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// checkForStmt reports every time.After call expression inside the loop body.
+// The walk recurses through all expression positions, so it covers direct
+// statements (<-time.After(d)), select cases, assignments, and time.After
+// used as an argument to another call (foo(time.After(d))) - all of which are
+// evaluated once per iteration and leak a timer the same way. Calls inside
+// function literals defined in the loop body are also reported, even though
+// those only leak if the literal is invoked per iteration.
+func checkForStmt(pass *analysis.Pass, body *ast.BlockStmt, pkgAliases []string, inctimerAvailable bool) {
+	ast.Walk(visitor(func(node ast.Node) bool {
+		switch expr := node.(type) {
+		case *ast.CallExpr:
+			for _, pkg := range pkgAliases {
+				if isPkgDot(expr.Fun, pkg, timeAfterFunc) {
+					diag := analysis.Diagnostic{
+						Pos:     node.Pos(),
+						Message: fmt.Sprintf("use of %s.After in a for loop is prohibited, use inctimer instead", pkg),
+					}
+					if inctimerAvailable {
+						diag.SuggestedFixes = []analysis.SuggestedFix{{
+							Message: "replace with inctimer.After",
+							TextEdits: []analysis.TextEdit{{
+								Pos:     expr.Fun.Pos(),
+								End:     expr.Fun.End(),
+								NewText: []byte("inctimer.After"),
+							}},
+						}}
+					}
+					pass.Report(diag)
+				}
+			}
+		}
+		return true
+	}), body)
+}
+
+// checkNewTimerStmt flags time.NewTimer calls in the loop body whose returned
+// timer is never Stop()ped within the block it is assigned in. This is a
+// heuristic: Stop calls via aliases or from other blocks are not tracked.
+func checkNewTimerStmt(pass *analysis.Pass, body *ast.BlockStmt, pkgAliases []string) {
+	ast.Walk(visitor(func(node ast.Node) bool {
+		block, ok := node.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+
+		type timerDecl struct {
+			pkg  string
+			name string
+			pos  token.Pos
+		}
+		var timers []timerDecl
+		stopped := make(map[string]struct{})
+
+		for _, stmt := range block.List {
+			if assign, ok := stmt.(*ast.AssignStmt); ok {
+				for i, rhs := range assign.Rhs {
+					call, ok := rhs.(*ast.CallExpr)
+					if !ok || i >= len(assign.Lhs) {
+						continue
+					}
+					ident, ok := assign.Lhs[i].(*ast.Ident)
+					if !ok || ident.Name == "_" {
+						continue
+					}
+					for _, pkg := range pkgAliases {
+						if isPkgDot(call.Fun, pkg, timeNewTimerFunc) {
+							timers = append(timers, timerDecl{pkg: pkg, name: ident.Name, pos: call.Pos()})
+						}
+					}
+				}
+			}
+			// Collect Stop() calls anywhere in the statement, including
+			// defers and nested blocks.
+			ast.Walk(visitor(func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || !isIdent(sel.Sel, timerStopMethod) {
+					return true
+				}
+				if id, ok := sel.X.(*ast.Ident); ok {
+					stopped[id.Name] = struct{}{}
+				}
+				return true
+			}), stmt)
+		}
+
+		for _, timer := range timers {
+			if _, ok := stopped[timer.name]; !ok {
+				pass.Reportf(timer.pos, "use of %s.NewTimer in a for loop without stopping the returned timer is prohibited", timer.pkg)
+			}
+		}
+		return true
+	}), body)
+}
+
+func isPkgDot(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	res := ok && isIdent(sel.X, pkg) && isIdent(sel.Sel, name)
+	return res
+}
+
+func isIdent(expr ast.Expr, ident string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == ident
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package timeafter
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// setFlag sets an analyzer flag for the duration of the test.
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+	prev := Analyzer.Flags.Lookup(name).Value.String()
+	if err := Analyzer.Flags.Set(name, value); err != nil {
+		t.Fatalf("setting -%s=%s: %s", name, value, err)
+	}
+	t.Cleanup(func() { Analyzer.Flags.Set(name, prev) })
+}
+
+func TestCheckNewTimer(t *testing.T) {
+	setFlag(t, "check-newtimer", "true")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "newtimer")
+}
+
+func TestCheckNewTimerDisabled(t *testing.T) {
+	// The check is opt-in: without the flag the unstopped timer passes. The
+	// fixture's expectations are inverted by loading it without diagnostics
+	// being reported, which analysistest flags as unmatched, so analyze a
+	// copy of the results instead.
+	results := analysistest.Run(nopT{}, analysistest.TestData(), Analyzer, "newtimer")
+	for _, result := range results {
+		for _, diag := range result.Diagnostics {
+			t.Errorf("check disabled: unexpected diagnostic: %s", diag.Message)
+		}
+	}
+}
+
+func TestIgnorePaths(t *testing.T) {
+	setFlag(t, "ignore-paths", "generated/fixture")
+	// The ignored import path produces no diagnostics; the same-named
+	// package under a different path is still reported.
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "generated/fixture", "other/fixture")
+}
+
+func TestTimeAfterAsArgument(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "timeafterarg")
+}
+
+func TestSuggestedFixRequiresInctimer(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer, "withinctimer", "withoutinctimer")
+	for _, result := range results {
+		for _, diag := range result.Diagnostics {
+			switch path := result.Pass.Pkg.Path(); path {
+			case "withinctimer":
+				if len(diag.SuggestedFixes) == 0 {
+					t.Errorf("%s: diagnostic carries no suggested fix despite inctimer being importable", path)
+				}
+			case "withoutinctimer":
+				// The rewrite to inctimer.After would not compile here.
+				if len(diag.SuggestedFixes) != 0 {
+					t.Errorf("%s: diagnostic carries a suggested fix: %v", path, diag.SuggestedFixes)
+				}
+			}
+		}
+	}
+}
+
+// nopT swallows analysistest's want-comment mismatch reports for tests that
+// assert the absence of diagnostics instead.
+type nopT struct{}
+
+func (nopT) Errorf(string, ...interface{}) {}
@@ -323,6 +323,15 @@ require (
 // as to why we are using a private fork.
 replace sigs.k8s.io/controller-tools => github.com/cilium/controller-tools v0.16.5-1
 
+// In-tree forks carrying local additions until they land upstream. Keeping
+// them outside vendor/ means `go mod vendor` regenerates from the forks
+// instead of silently dropping the changes, and their tests stay runnable
+// (vendor never holds _test.go files).
+replace (
+	github.com/cilium/ebpf => ./forks/ebpf
+	github.com/cilium/linters => ./forks/linters
+)
+
 tool (
 	github.com/cilium/deepequal-gen
 	github.com/cilium/linters
@@ -47,6 +47,11 @@ const (
 	PolicyName      = PolicyPrefix + "/name"
 	PolicyNameAlias = Prefix + ".name"
 
+	// PolicyPriority is an optional annotation declaring a numeric priority
+	// for a CiliumNetworkPolicy, read by external tooling to order policies
+	// deterministically. It does not affect enforcement.
+	PolicyPriority = PolicyPrefix + "/priority"
+
 	// V4CIDRName / V4CIDRNameAlias is the annotation name used to store the IPv4
 	// pod CIDR in the node's annotations.
 	V4CIDRName      = NetworkPrefix + "/ipv4-pod-cidr"
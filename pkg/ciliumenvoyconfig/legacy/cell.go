@@ -34,7 +34,9 @@ var Cell = cell.Module(
 
 	cell.Invoke(registerCECK8sReconciler),
 	cell.ProvidePrivate(newCECManager),
-	cell.ProvidePrivate(newEnvoyServiceBackendSyncer),
+	cell.ProvidePrivate(newBackendSyncer),
+
+	cell.Config(defaultSyncerConfig),
 )
 
 type reconcilerParams struct {
@@ -136,6 +138,24 @@ type managerParams struct {
 	MetricsManager ciliumenvoyconfig.FeatureMetrics
 }
 
+type syncerParams struct {
+	cell.In
+
+	Logger *slog.Logger
+
+	Config    syncerConfig
+	XdsServer envoy.XDSServer
+
+	// ClusterNameFormat is absent in the default agent wiring; agents
+	// embedding Cilium can provide it to override the Envoy cluster naming
+	// scheme.
+	ClusterNameFormat ClusterNameFormat `optional:"true"`
+}
+
+func newBackendSyncer(params syncerParams) *envoyServiceBackendSyncer {
+	return newEnvoyServiceBackendSyncer(params.Logger, params.XdsServer, params.Config, params.ClusterNameFormat)
+}
+
 func newCECManager(params managerParams) ciliumEnvoyConfigManager {
 	return newCiliumEnvoyConfigManager(params.Logger, params.PolicyUpdater, params.ServiceManager, params.XdsServer,
 		params.BackendSyncer, params.ResourceParser, params.Config.EnvoyConfigTimeout, params.EnvoyConfig.ProxyMaxConcurrentRetries, params.Services, params.Endpoints, params.MetricsManager)
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package legacy
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// ClusterNameFormat overrides how per-port Envoy cluster names are built from
+// the service name and frontend port. It can be provided optionally by agents
+// embedding Cilium whose generated CEC resources use a different naming
+// scheme; when absent the default "<service>:<port>" format is used.
+type ClusterNameFormat func(serviceName loadbalancer.ServiceName, port string) string
+
+// syncerConfig configures how the backends of L7LB services are synced to
+// Envoy as endpoints.
+type syncerConfig struct {
+	EmitConnectTimeoutMetadata bool          `mapstructure:"envoy-lb-emit-connect-timeout-metadata"`
+	MaxBackendsPerService      int           `mapstructure:"envoy-lb-max-backends-per-service"`
+	StrictBackendSync          bool          `mapstructure:"envoy-lb-strict-backend-sync"`
+	EmitHashKeyMetadata        bool          `mapstructure:"envoy-lb-emit-hash-key-metadata"`
+	EmitAnyPortCompatCluster   bool          `mapstructure:"envoy-lb-emit-any-port-compat-cluster"`
+	EmitPerProtocolClusters    bool          `mapstructure:"envoy-lb-emit-per-protocol-clusters"`
+	EmitLocalityWeights        bool          `mapstructure:"envoy-lb-emit-locality-weights"`
+	BackendGracePeriod         time.Duration `mapstructure:"envoy-lb-backend-grace-period"`
+}
+
+// defaultSyncerConfig is registered as the cell default and mirrored by the
+// flag defaults below.
+var defaultSyncerConfig = syncerConfig{
+	EmitAnyPortCompatCluster: true,
+}
+
+func (c syncerConfig) Flags(flags *pflag.FlagSet) {
+	flags.Bool("envoy-lb-emit-connect-timeout-metadata", c.EmitConnectTimeoutMetadata, "Attach configured per-backend connect-timeout hints as endpoint metadata on backends synced to Envoy")
+	flags.Int("envoy-lb-max-backends-per-service", c.MaxBackendsPerService, "Maximum number of backends synced to Envoy per service and port. When the cap is exceeded, a deterministic subset of the backends is selected. 0 means no limit.")
+	flags.Bool("envoy-lb-strict-backend-sync", c.StrictBackendSync, "Surface an L7LB service left without any eligible backends as a sync error instead of silently syncing the empty endpoint set")
+	flags.Bool("envoy-lb-emit-hash-key-metadata", c.EmitHashKeyMetadata, "Attach the backend ID as \"envoy.lb\" hash key endpoint metadata for use with ring-hash and maglev load balancing policies")
+	flags.Bool("envoy-lb-emit-any-port-compat-cluster", c.EmitAnyPortCompatCluster, "Publish the backward-compat duplicate cluster named after the bare service alongside the \"<service>:*\" cluster when any port is allowed")
+	flags.Bool("envoy-lb-emit-per-protocol-clusters", c.EmitPerProtocolClusters, "Split ports serving both TCP and UDP into protocol-suffixed Envoy clusters instead of dropping the UDP backends")
+	flags.Bool("envoy-lb-emit-locality-weights", c.EmitLocalityWeights, "Group backends synced to Envoy by zone into locality endpoints weighted by backend count")
+	flags.Duration("envoy-lb-backend-grace-period", c.BackendGracePeriod, "Duration for which newly seen backends are synced to Envoy as DEGRADED, so established backends are preferred while new ones warm up. 0 disables the grace period.")
+}
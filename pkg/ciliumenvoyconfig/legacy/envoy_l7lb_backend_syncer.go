@@ -5,12 +5,17 @@ package legacy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
+	"sort"
 	"strconv"
 
 	envoy_config_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_config_endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/cilium/cilium/pkg/envoy"
 	"github.com/cilium/cilium/pkg/loadbalancer"
@@ -18,10 +23,34 @@ import (
 	"github.com/cilium/cilium/pkg/lock"
 	"github.com/cilium/cilium/pkg/logging/logfields"
 	"github.com/cilium/cilium/pkg/slices"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 const anyPort = "*"
 
+// ErrNoServiceBackends is returned by Sync in strict mode when a tracked L7LB
+// service has no eligible backends left after filtering.
+var ErrNoServiceBackends = errors.New("no eligible backends for L7LB service")
+
+const (
+	// ciliumEndpointMetadataNamespace is the FilterMetadata namespace under
+	// which Cilium attaches per-endpoint attributes for consumption in CEC
+	// cluster config.
+	ciliumEndpointMetadataNamespace = "io.cilium.lb"
+
+	// connectTimeoutMetadataKey carries the per-backend connect-timeout hint
+	// in seconds.
+	connectTimeoutMetadataKey = "connect-timeout-seconds"
+
+	// envoyLbMetadataNamespace is Envoy's own LB metadata namespace, consulted
+	// by ring-hash and maglev load balancing policies.
+	envoyLbMetadataNamespace = "envoy.lb"
+
+	// hashKeyMetadataKey is the key under envoyLbMetadataNamespace holding the
+	// stable per-endpoint hash key.
+	hashKeyMetadataKey = "hash_key"
+)
+
 // envoyServiceBackendSyncer syncs the backends of a Service as Endpoints to the Envoy L7 proxy.
 type envoyServiceBackendSyncer struct {
 	logger *slog.Logger
@@ -30,6 +59,69 @@ type envoyServiceBackendSyncer struct {
 
 	l7lbSvcsMutex lock.RWMutex
 	l7lbSvcs      map[loadbalancer.ServiceName]*backendSyncInfo
+
+	// emitConnectTimeoutMetadata controls whether configured per-backend
+	// connect-timeout hints are attached as endpoint metadata. Disabled by
+	// default.
+	emitConnectTimeoutMetadata bool
+
+	// maxBackendsPerService caps the number of backends synced to Envoy per
+	// service and port. Zero means no limit (the default). When the cap is
+	// exceeded, a deterministic subset of the backends is selected.
+	maxBackendsPerService int
+
+	// clusterNameFormat overrides how per-port Envoy cluster names are built
+	// from the service name and frontend port. Nil (the default) uses the
+	// "<service>:<port>" scheme expected by generated CEC resources.
+	clusterNameFormat func(serviceName loadbalancer.ServiceName, port string) string
+
+	// strictBackendSync makes Sync return ErrNoServiceBackends when a tracked
+	// service ends up with no eligible backends at all. Disabled by default:
+	// an empty backend set is then synced silently, as before.
+	strictBackendSync bool
+
+	// emitHashKeyMetadata attaches the backend ID as a stable hash key under
+	// Envoy's "envoy.lb" endpoint metadata, so CEC cluster policies can use
+	// ring-hash or maglev load balancing with consistent endpoint placement.
+	// Disabled by default.
+	emitHashKeyMetadata bool
+
+	// emitAnyPortCompatCluster controls whether the backward-compat duplicate
+	// cluster named after the bare service is published alongside the
+	// "<service>:*" cluster when any port is allowed. Enabled by default;
+	// deployments whose CECs only reference port-qualified clusters can turn
+	// it off to halve the endpoint payload for such services.
+	emitAnyPortCompatCluster bool
+
+	// emitPerProtocolClusters controls whether ports serving both TCP and
+	// UDP are split into protocol-suffixed clusters ("<service>:<port>:tcp"
+	// and "<service>:<port>:udp") instead of dropping the UDP backends.
+	// Disabled by default, which keeps the historical TCP-only behavior.
+	emitPerProtocolClusters bool
+
+	// emitLocalityWeights controls whether backends are grouped by zone into
+	// separate LocalityLbEndpoints entries weighted by their backend count,
+	// so Envoy's locality-weighted load balancing approximates an even
+	// per-backend spread across unevenly sized zones. Disabled by default,
+	// which keeps all backends in a single unweighted locality group.
+	emitLocalityWeights bool
+
+	// backendGracePeriod, when positive, emits backends first seen less than
+	// this long ago with HealthStatus DEGRADED, so Envoy prefers established
+	// backends until new ones have had time to warm up. Zero (the default)
+	// disables the grace period.
+	backendGracePeriod time.Duration
+
+	// now returns the current time; tests replace it with a fake clock.
+	now func() time.Time
+
+	backendAttrsMutex            lock.RWMutex
+	backendConnectTimeoutSeconds map[string]uint32 // keyed by backend address
+	// backendFirstSeen tracks when a backend address was first synced for a
+	// service. Entries are pruned once the address disappears from the
+	// service's backend set, so a backend that returns after an absence goes
+	// through the grace period again.
+	backendFirstSeen map[loadbalancer.ServiceName]map[string]time.Time
 }
 
 var _ service.BackendSyncer = &envoyServiceBackendSyncer{}
@@ -38,14 +130,129 @@ func (*envoyServiceBackendSyncer) ProxyName() string {
 	return "Envoy"
 }
 
-func newEnvoyServiceBackendSyncer(logger *slog.Logger, envoyXdsServer envoy.XDSServer) *envoyServiceBackendSyncer {
+func newEnvoyServiceBackendSyncer(logger *slog.Logger, envoyXdsServer envoy.XDSServer, config syncerConfig, clusterNameFormat ClusterNameFormat) *envoyServiceBackendSyncer {
 	return &envoyServiceBackendSyncer{
-		logger:         logger,
-		envoyXdsServer: envoyXdsServer,
-		l7lbSvcs:       map[loadbalancer.ServiceName]*backendSyncInfo{},
+		logger:                       logger,
+		envoyXdsServer:               envoyXdsServer,
+		l7lbSvcs:                     map[loadbalancer.ServiceName]*backendSyncInfo{},
+		backendConnectTimeoutSeconds: map[string]uint32{},
+		backendFirstSeen:             map[loadbalancer.ServiceName]map[string]time.Time{},
+		now:                          time.Now,
+		emitConnectTimeoutMetadata:   config.EmitConnectTimeoutMetadata,
+		maxBackendsPerService:        config.MaxBackendsPerService,
+		clusterNameFormat:            clusterNameFormat,
+		strictBackendSync:            config.StrictBackendSync,
+		emitHashKeyMetadata:          config.EmitHashKeyMetadata,
+		emitAnyPortCompatCluster:     config.EmitAnyPortCompatCluster,
+		emitPerProtocolClusters:      config.EmitPerProtocolClusters,
+		emitLocalityWeights:          config.EmitLocalityWeights,
+		backendGracePeriod:           config.BackendGracePeriod,
 	}
 }
 
+// SetBackendConnectTimeout records a connect-timeout hint in seconds for the
+// backend with the given address. A zero value clears the hint. The hint is
+// only surfaced to Envoy when connect-timeout metadata emission is enabled.
+func (r *envoyServiceBackendSyncer) SetBackendConnectTimeout(addr loadbalancer.L3n4Addr, seconds uint32) {
+	r.backendAttrsMutex.Lock()
+	defer r.backendAttrsMutex.Unlock()
+
+	if seconds == 0 {
+		delete(r.backendConnectTimeoutSeconds, addr.String())
+		return
+	}
+	r.backendConnectTimeoutSeconds[addr.String()] = seconds
+}
+
+// inStartupGrace reports whether the given backend is still within the
+// startup grace period. The first time a backend address is seen it is
+// recorded as newly started; subsequent syncs compare against that
+// timestamp.
+func (r *envoyServiceBackendSyncer) inStartupGrace(serviceName loadbalancer.ServiceName, be *loadbalancer.LegacyBackend) bool {
+	if r.backendGracePeriod <= 0 {
+		return false
+	}
+
+	addr := be.L3n4Addr.String()
+	now := r.now()
+
+	r.backendAttrsMutex.Lock()
+	seen := r.backendFirstSeen[serviceName]
+	if seen == nil {
+		seen = map[string]time.Time{}
+		r.backendFirstSeen[serviceName] = seen
+	}
+	firstSeen, ok := seen[addr]
+	if !ok {
+		seen[addr] = now
+		firstSeen = now
+	}
+	r.backendAttrsMutex.Unlock()
+
+	return now.Sub(firstSeen) < r.backendGracePeriod
+}
+
+// pruneFirstSeen drops first-seen timestamps of backend addresses no longer
+// part of the service's backend set, bounding the map under backend churn and
+// restarting the grace period should an address come back later.
+func (r *envoyServiceBackendSyncer) pruneFirstSeen(serviceName loadbalancer.ServiceName, backendMap map[string][]*loadbalancer.LegacyBackend) {
+	r.backendAttrsMutex.Lock()
+	defer r.backendAttrsMutex.Unlock()
+
+	seen := r.backendFirstSeen[serviceName]
+	if len(seen) == 0 {
+		return
+	}
+
+	current := map[string]struct{}{}
+	for _, bes := range backendMap {
+		for _, be := range bes {
+			current[be.L3n4Addr.String()] = struct{}{}
+		}
+	}
+	for addr := range seen {
+		if _, ok := current[addr]; !ok {
+			delete(seen, addr)
+		}
+	}
+	if len(seen) == 0 {
+		delete(r.backendFirstSeen, serviceName)
+	}
+}
+
+// endpointMetadataForBackend returns the endpoint metadata to attach for the
+// given backend, or nil if there is none to attach.
+func (r *envoyServiceBackendSyncer) endpointMetadataForBackend(be *loadbalancer.LegacyBackend) *envoy_config_core.Metadata {
+	filterMetadata := map[string]*structpb.Struct{}
+
+	if r.emitConnectTimeoutMetadata {
+		r.backendAttrsMutex.RLock()
+		seconds, exists := r.backendConnectTimeoutSeconds[be.L3n4Addr.String()]
+		r.backendAttrsMutex.RUnlock()
+		if exists {
+			filterMetadata[ciliumEndpointMetadataNamespace] = &structpb.Struct{
+				Fields: map[string]*structpb.Value{
+					connectTimeoutMetadataKey: structpb.NewNumberValue(float64(seconds)),
+				},
+			}
+		}
+	}
+
+	// Backends without an ID carry no stable identity to hash on.
+	if r.emitHashKeyMetadata && be.ID != 0 {
+		filterMetadata[envoyLbMetadataNamespace] = &structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				hashKeyMetadataKey: structpb.NewStringValue(strconv.FormatUint(uint64(be.ID), 10)),
+			},
+		}
+	}
+
+	if len(filterMetadata) == 0 {
+		return nil
+	}
+	return &envoy_config_core.Metadata{FilterMetadata: filterMetadata}
+}
+
 func (r *envoyServiceBackendSyncer) Sync(svc *loadbalancer.LegacySVC) error {
 	r.l7lbSvcsMutex.RLock()
 	l7lbInfo, exists := r.l7lbSvcs[svc.Name]
@@ -59,6 +266,10 @@ func (r *envoyServiceBackendSyncer) Sync(svc *loadbalancer.LegacySVC) error {
 	// Filter backend based on list of port numbers, then upsert backends
 	// as Envoy endpoints
 	be := filterServiceBackends(svc, frontendPorts)
+	for port, bes := range be {
+		be[port] = r.capBackends(svc.Name, port, bes)
+	}
+	r.pruneFirstSeen(svc.Name, be)
 
 	r.logger.Debug("Upsert envoy endpoints",
 		logfields.L7LBFrontendPorts, frontendPorts,
@@ -69,6 +280,18 @@ func (r *envoyServiceBackendSyncer) Sync(svc *loadbalancer.LegacySVC) error {
 		return fmt.Errorf("failed to update backends in Envoy: %w", err)
 	}
 
+	if r.strictBackendSync {
+		total := 0
+		for _, bes := range be {
+			total += len(bes)
+		}
+		// The (empty) endpoint set has been synced above so Envoy does not
+		// keep stale backends; the error only serves to surface the state.
+		if total == 0 {
+			return fmt.Errorf("%w: %s", ErrNoServiceBackends, svc.Name.String())
+		}
+	}
+
 	return nil
 }
 
@@ -93,6 +316,27 @@ func (r *envoyServiceBackendSyncer) RegisterServiceUsageInCEC(svcName loadbalanc
 	r.l7lbSvcs[svcName] = l7lbInfo
 }
 
+// RegisterPortRemapInCEC records, for an already registered CEC, which
+// frontend ports map to a different backend target port. Synced endpoints for
+// a remapped port then carry the target port instead of the backend's own
+// port. Must be called after RegisterServiceUsageInCEC for the same resource,
+// which resets any previously registered remaps.
+func (r *envoyServiceBackendSyncer) RegisterPortRemapInCEC(svcName loadbalancer.ServiceName, resourceName service.L7LBResourceName, remaps map[string]uint16) {
+	r.l7lbSvcsMutex.Lock()
+	defer r.l7lbSvcsMutex.Unlock()
+
+	l7lbInfo, exists := r.l7lbSvcs[svcName]
+	if !exists || l7lbInfo.backendRefs == nil {
+		return
+	}
+	info, exists := l7lbInfo.backendRefs[resourceName]
+	if !exists {
+		return
+	}
+	info.portRemaps = remaps
+	l7lbInfo.backendRefs[resourceName] = info
+}
+
 func (r *envoyServiceBackendSyncer) DeregisterServiceUsageInCEC(svcName loadbalancer.ServiceName, resourceName service.L7LBResourceName) bool {
 	r.l7lbSvcsMutex.Lock()
 	defer r.l7lbSvcsMutex.Unlock()
@@ -110,6 +354,9 @@ func (r *envoyServiceBackendSyncer) DeregisterServiceUsageInCEC(svcName loadbala
 	// Cleanup service if it's no longer used by any CEC
 	if len(l7lbInfo.backendRefs) == 0 {
 		delete(r.l7lbSvcs, svcName)
+		r.backendAttrsMutex.Lock()
+		delete(r.backendFirstSeen, svcName)
+		r.backendAttrsMutex.Unlock()
 		return true
 	}
 
@@ -118,21 +365,87 @@ func (r *envoyServiceBackendSyncer) DeregisterServiceUsageInCEC(svcName loadbala
 	return false
 }
 
+// AllRegisteredPorts returns the sorted unique union of the frontend ports of
+// every service currently tracked for backend syncing.
+func (r *envoyServiceBackendSyncer) AllRegisteredPorts() []string {
+	r.l7lbSvcsMutex.RLock()
+	defer r.l7lbSvcsMutex.RUnlock()
+
+	allPorts := []string{}
+	for _, l7lbInfo := range r.l7lbSvcs {
+		allPorts = append(allPorts, l7lbInfo.GetAllFrontendPorts()...)
+	}
+
+	return slices.SortedUnique(allPorts)
+}
+
 func (r *envoyServiceBackendSyncer) upsertEnvoyEndpoints(serviceName loadbalancer.ServiceName, backendMap map[string][]*loadbalancer.LegacyBackend) error {
 	var resources envoy.Resources
 
-	resources.Endpoints = getEndpointsForLBBackends(serviceName, backendMap)
+	resources.Endpoints = r.getEndpointsForLBBackends(serviceName, backendMap)
 
 	// Using context.TODO() is fine as we do not upsert listener resources here - the
 	// context ends up being used only if listener(s) are included in 'resources'.
 	return r.envoyXdsServer.UpsertEnvoyResources(context.TODO(), resources)
 }
 
-func getEndpointsForLBBackends(serviceName loadbalancer.ServiceName, backendMap map[string][]*loadbalancer.LegacyBackend) []*envoy_config_endpoint.ClusterLoadAssignment {
+// portRemapFor returns the CEC-declared backend target port for the given
+// service and frontend port, or 0 when the port is not remapped.
+func (r *envoyServiceBackendSyncer) portRemapFor(serviceName loadbalancer.ServiceName, port string) uint16 {
+	r.l7lbSvcsMutex.RLock()
+	defer r.l7lbSvcsMutex.RUnlock()
+
+	l7lbInfo, exists := r.l7lbSvcs[serviceName]
+	if !exists {
+		return 0
+	}
+	return l7lbInfo.GetPortRemap(port)
+}
+
+func (r *envoyServiceBackendSyncer) getEndpointsForLBBackends(serviceName loadbalancer.ServiceName, backendMap map[string][]*loadbalancer.LegacyBackend) []*envoy_config_endpoint.ClusterLoadAssignment {
 	var endpoints []*envoy_config_endpoint.ClusterLoadAssignment
 
 	for port, bes := range backendMap {
-		var lbEndpoints []*envoy_config_endpoint.LbEndpoint
+		targetPort := r.portRemapFor(serviceName, port)
+		// Mixed-protocol ports get one cluster per protocol, so Envoy can
+		// route TCP and UDP traffic to the same port independently.
+		if r.emitPerProtocolClusters {
+			var tcpBes, udpBes []*loadbalancer.LegacyBackend
+			for _, be := range bes {
+				switch be.Protocol {
+				case loadbalancer.UDP:
+					udpBes = append(udpBes, be)
+				case loadbalancer.SCTP:
+					// SCTP is not supported by Envoy.
+				default:
+					tcpBes = append(tcpBes, be)
+				}
+			}
+			if len(tcpBes) > 0 {
+				endpoints = append(endpoints, &envoy_config_endpoint.ClusterLoadAssignment{
+					ClusterName: r.clusterName(serviceName, port) + ":tcp",
+					Endpoints:   r.localityEndpointsForBackends(serviceName, tcpBes, targetPort),
+				})
+			}
+			if len(udpBes) > 0 {
+				endpoints = append(endpoints, &envoy_config_endpoint.ClusterLoadAssignment{
+					ClusterName: r.clusterName(serviceName, port) + ":udp",
+					Endpoints:   r.localityEndpointsForBackends(serviceName, udpBes, targetPort),
+				})
+			}
+			// The backward-compat cluster named after the bare service is
+			// still published for the any-port case, with its historical
+			// TCP-only contents.
+			if port == anyPort && r.emitAnyPortCompatCluster {
+				endpoints = append(endpoints, &envoy_config_endpoint.ClusterLoadAssignment{
+					ClusterName: serviceName.String(),
+					Endpoints:   r.localityEndpointsForBackends(serviceName, tcpBes, targetPort),
+				})
+			}
+			continue
+		}
+
+		var kept []*loadbalancer.LegacyBackend
 		for _, be := range bes {
 			// The below is to make sure that UDP and SCTP are not allowed instead of comparing with lb.TCP
 			// The reason is to avoid extra dependencies with ongoing work to differentiate protocols in datapath,
@@ -140,45 +453,22 @@ func getEndpointsForLBBackends(serviceName loadbalancer.ServiceName, backendMap
 			if be.Protocol == loadbalancer.UDP || be.Protocol == loadbalancer.SCTP {
 				continue
 			}
-
-			lbEndpoints = append(lbEndpoints, &envoy_config_endpoint.LbEndpoint{
-				HostIdentifier: &envoy_config_endpoint.LbEndpoint_Endpoint{
-					Endpoint: &envoy_config_endpoint.Endpoint{
-						Address: &envoy_config_core.Address{
-							Address: &envoy_config_core.Address_SocketAddress{
-								SocketAddress: &envoy_config_core.SocketAddress{
-									Address: be.AddrCluster.String(),
-									PortSpecifier: &envoy_config_core.SocketAddress_PortValue{
-										PortValue: uint32(be.Port),
-									},
-								},
-							},
-						},
-					},
-				},
-			})
+			kept = append(kept, be)
 		}
+		localityEndpoints := r.localityEndpointsForBackends(serviceName, kept, targetPort)
 
 		endpoint := &envoy_config_endpoint.ClusterLoadAssignment{
-			ClusterName: fmt.Sprintf("%s:%s", serviceName.String(), port),
-			Endpoints: []*envoy_config_endpoint.LocalityLbEndpoints{
-				{
-					LbEndpoints: lbEndpoints,
-				},
-			},
+			ClusterName: r.clusterName(serviceName, port),
+			Endpoints:   localityEndpoints,
 		}
 		endpoints = append(endpoints, endpoint)
 
 		// for backward compatibility, if any port is allowed, publish one more
 		// endpoint having cluster name as service name.
-		if port == anyPort {
+		if port == anyPort && r.emitAnyPortCompatCluster {
 			endpoints = append(endpoints, &envoy_config_endpoint.ClusterLoadAssignment{
 				ClusterName: serviceName.String(),
-				Endpoints: []*envoy_config_endpoint.LocalityLbEndpoints{
-					{
-						LbEndpoints: lbEndpoints,
-					},
-				},
+				Endpoints:   localityEndpoints,
 			})
 		}
 	}
@@ -186,6 +476,119 @@ func getEndpointsForLBBackends(serviceName loadbalancer.ServiceName, backendMap
 	return endpoints
 }
 
+// localityEndpointsForBackends builds the LocalityLbEndpoints entries for
+// the given backends. By default they all land in a single unweighted group;
+// with emitLocalityWeights set, each zone becomes its own group weighted by
+// its backend count.
+func (r *envoyServiceBackendSyncer) localityEndpointsForBackends(serviceName loadbalancer.ServiceName, bes []*loadbalancer.LegacyBackend, targetPort uint16) []*envoy_config_endpoint.LocalityLbEndpoints {
+	if !r.emitLocalityWeights {
+		return []*envoy_config_endpoint.LocalityLbEndpoints{
+			{
+				LbEndpoints: r.lbEndpointsForBackends(serviceName, bes, targetPort),
+			},
+		}
+	}
+
+	byZone := map[uint8][]*loadbalancer.LegacyBackend{}
+	for _, be := range bes {
+		byZone[be.ZoneID] = append(byZone[be.ZoneID], be)
+	}
+
+	zones := make([]uint8, 0, len(byZone))
+	for zone := range byZone {
+		zones = append(zones, zone)
+	}
+
+	var localityEndpoints []*envoy_config_endpoint.LocalityLbEndpoints
+	for _, zone := range slices.SortedUnique(zones) {
+		zoneBes := byZone[zone]
+		localityEndpoints = append(localityEndpoints, &envoy_config_endpoint.LocalityLbEndpoints{
+			LbEndpoints:         r.lbEndpointsForBackends(serviceName, zoneBes, targetPort),
+			LoadBalancingWeight: wrapperspb.UInt32(uint32(len(zoneBes))),
+		})
+	}
+	return localityEndpoints
+}
+
+// lbEndpointsForBackends builds the LbEndpoint entries for the given
+// backends. A non-zero targetPort overrides each backend's own port, for
+// frontend ports a CEC remaps. Protocol filtering is up to the caller.
+func (r *envoyServiceBackendSyncer) lbEndpointsForBackends(serviceName loadbalancer.ServiceName, bes []*loadbalancer.LegacyBackend, targetPort uint16) []*envoy_config_endpoint.LbEndpoint {
+	var lbEndpoints []*envoy_config_endpoint.LbEndpoint
+	for _, be := range bes {
+		port := be.Port
+		if targetPort != 0 {
+			port = targetPort
+		}
+		lbEndpoint := &envoy_config_endpoint.LbEndpoint{
+			HostIdentifier: &envoy_config_endpoint.LbEndpoint_Endpoint{
+				Endpoint: &envoy_config_endpoint.Endpoint{
+					Address: &envoy_config_core.Address{
+						Address: &envoy_config_core.Address_SocketAddress{
+							SocketAddress: &envoy_config_core.SocketAddress{
+								Address: be.AddrCluster.String(),
+								PortSpecifier: &envoy_config_core.SocketAddress_PortValue{
+									PortValue: uint32(port),
+								},
+							},
+						},
+					},
+				},
+			},
+			Metadata: r.endpointMetadataForBackend(be),
+		}
+		if r.inStartupGrace(serviceName, be) {
+			lbEndpoint.HealthStatus = envoy_config_core.HealthStatus_DEGRADED
+		}
+		lbEndpoints = append(lbEndpoints, lbEndpoint)
+	}
+	return lbEndpoints
+}
+
+// clusterName builds the Envoy cluster name for the given service and
+// frontend port, honoring a custom formatter when one is configured.
+func (r *envoyServiceBackendSyncer) clusterName(serviceName loadbalancer.ServiceName, port string) string {
+	if r.clusterNameFormat != nil {
+		return r.clusterNameFormat(serviceName, port)
+	}
+	return fmt.Sprintf("%s:%s", serviceName.String(), port)
+}
+
+// capBackends limits bes to at most maxBackendsPerService entries. Backends
+// are ranked by a hash of their address, so the selected subset is
+// deterministic for a given backend set and mostly stable as backends are
+// added or removed.
+func (r *envoyServiceBackendSyncer) capBackends(svcName loadbalancer.ServiceName, port string, bes []*loadbalancer.LegacyBackend) []*loadbalancer.LegacyBackend {
+	if r.maxBackendsPerService <= 0 || len(bes) <= r.maxBackendsPerService {
+		return bes
+	}
+
+	rank := func(be *loadbalancer.LegacyBackend) uint64 {
+		h := fnv.New64a()
+		h.Write([]byte(be.L3n4Addr.String()))
+		return h.Sum64()
+	}
+	ranked := make([]*loadbalancer.LegacyBackend, len(bes))
+	copy(ranked, bes)
+	sort.Slice(ranked, func(i, j int) bool {
+		hi, hj := rank(ranked[i]), rank(ranked[j])
+		if hi != hj {
+			return hi < hj
+		}
+		return ranked[i].L3n4Addr.String() < ranked[j].L3n4Addr.String()
+	})
+
+	r.logger.Warn("Truncating backends synced to Envoy",
+		logfields.ServiceNamespace, svcName.Namespace,
+		logfields.ServiceName, svcName.Name,
+		logfields.Port, port,
+		logfields.Backends, len(bes),
+		logfields.Limit, r.maxBackendsPerService,
+	)
+
+	return ranked[:r.maxBackendsPerService]
+}
+
 // filterServiceBackends returns the list of backends based on given front end ports.
 // The returned map will have key as port name/number, and value as list of respective backends.
 func filterServiceBackends(svc *loadbalancer.LegacySVC, onlyPorts []string) map[string][]*loadbalancer.LegacyBackend {
@@ -248,10 +651,36 @@ func (r *backendSyncInfo) GetAllFrontendPorts() []string {
 	return slices.SortedUnique(allPorts)
 }
 
+// GetPortRemap returns the backend target port a registered CEC declares for
+// the given frontend port, or 0 when none does. Should several CECs disagree,
+// the lexicographically first resource wins, keeping the result
+// deterministic.
+func (r *backendSyncInfo) GetPortRemap(port string) uint16 {
+	var winner string
+	var target uint16
+	for name, info := range r.backendRefs {
+		t, ok := info.portRemaps[port]
+		if !ok {
+			continue
+		}
+		key := name.Namespace + "/" + name.Name
+		if winner == "" || key < winner {
+			winner = key
+			target = t
+		}
+	}
+	return target
+}
+
 type backendSyncCECInfo struct {
 	// List of front-end ports of upstream service/cluster, which will be used for
 	// filtering applicable endpoints.
 	//
 	// If nil, all the available backends will be used.
 	frontendPorts []string
+
+	// portRemaps maps frontend ports to a differing backend target port, as
+	// declared by the CEC. Endpoints for a remapped frontend port are emitted
+	// with the target port instead of the backend's own port.
+	portRemaps map[string]uint16
 }
@@ -4,9 +4,11 @@
 package legacy
 
 import (
+	"context"
 	"net/netip"
 	"testing"
 
+	"github.com/cilium/hive/hivetest"
 	envoy_config_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	_ "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
@@ -14,7 +16,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cilium/cilium/pkg/clustermesh/types"
+	"github.com/cilium/cilium/pkg/envoy"
 	"github.com/cilium/cilium/pkg/loadbalancer"
+	"github.com/cilium/cilium/pkg/loadbalancer/legacy/service"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 func Test_filterServiceBackends(t *testing.T) {
@@ -231,7 +236,8 @@ func TestGetEndpointsForLBBackends(t *testing.T) {
 		},
 	}
 
-	endpoints := getEndpointsForLBBackends(serviceName, backends)
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
 	assert.Len(t, endpoints, 4)
 
 	var allClusterNames []string
@@ -248,3 +254,606 @@ func TestGetEndpointsForLBBackends(t *testing.T) {
 	assert.Contains(t, allClusterNames, "test-cluster/test-ns/test-name:*")
 	assert.Contains(t, allClusterNames, "test-cluster/test-ns/test-name")
 }
+
+func TestGetEndpointsForLBBackendsConnectTimeoutMetadata(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	slowBackend := &loadbalancer.LegacyBackend{
+		L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 12000, 3),
+	}
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"12000": {slowBackend},
+	}
+
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	syncer.SetBackendConnectTimeout(slowBackend.L3n4Addr, 30)
+
+	// With emission disabled (the default), no metadata is attached.
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	require.Len(t, endpoints[0].GetEndpoints(), 1)
+	assert.Nil(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata())
+
+	// With emission enabled, the configured timeout is surfaced under the
+	// Cilium filter metadata namespace.
+	cfg := defaultSyncerConfig
+	cfg.EmitConnectTimeoutMetadata = true
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	syncer.SetBackendConnectTimeout(slowBackend.L3n4Addr, 30)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	md := endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata()
+	require.NotNil(t, md)
+	fields := md.GetFilterMetadata()[ciliumEndpointMetadataNamespace].GetFields()
+	assert.Equal(t, float64(30), fields[connectTimeoutMetadataKey].GetNumberValue())
+
+	// Backends without a configured timeout get no metadata even when enabled.
+	plainBackend := &loadbalancer.LegacyBackend{
+		L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 13000, 3),
+	}
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, map[string][]*loadbalancer.LegacyBackend{
+		"13000": {plainBackend},
+	})
+	require.Len(t, endpoints, 1)
+	assert.Nil(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata())
+
+	// Clearing the hint removes the metadata again.
+	syncer.SetBackendConnectTimeout(slowBackend.L3n4Addr, 0)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	assert.Nil(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata())
+}
+
+func TestEndpointHashKeyMetadata(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	hashedBackend := &loadbalancer.LegacyBackend{
+		ID:       42,
+		L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 12000, 3),
+	}
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"12000": {hashedBackend},
+	}
+
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+
+	// With emission disabled (the default), no metadata is attached.
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	assert.Nil(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata())
+
+	// With emission enabled, the backend ID appears as the hash key under
+	// Envoy's LB metadata namespace.
+	cfg := defaultSyncerConfig
+	cfg.EmitHashKeyMetadata = true
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	md := endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata()
+	require.NotNil(t, md)
+	fields := md.GetFilterMetadata()[envoyLbMetadataNamespace].GetFields()
+	assert.Equal(t, "42", fields[hashKeyMetadataKey].GetStringValue())
+
+	// Backends without an ID carry nothing stable to hash on and get no
+	// metadata even when enabled.
+	unhashedBackend := &loadbalancer.LegacyBackend{
+		L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 13000, 3),
+	}
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, map[string][]*loadbalancer.LegacyBackend{
+		"13000": {unhashedBackend},
+	})
+	require.Len(t, endpoints, 1)
+	assert.Nil(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetMetadata())
+}
+
+func TestCapBackends(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	var backends []*loadbalancer.LegacyBackend
+	for i := range 10 {
+		backends = append(backends, &loadbalancer.LegacyBackend{
+			L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), uint16(12000+i), 3),
+		})
+	}
+
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+
+	// Without a cap (the default), the backends pass through unchanged.
+	assert.Equal(t, backends, syncer.capBackends(serviceName, "8080", backends))
+
+	cfg := defaultSyncerConfig
+	cfg.MaxBackendsPerService = 4
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+
+	// Below the cap, the backends pass through unchanged.
+	assert.Equal(t, backends[:3], syncer.capBackends(serviceName, "8080", backends[:3]))
+
+	// Above the cap, the subset is truncated to the cap and stable across calls.
+	capped := syncer.capBackends(serviceName, "8080", backends)
+	assert.Len(t, capped, 4)
+	assert.Equal(t, capped, syncer.capBackends(serviceName, "8080", backends))
+
+	// The selection keys on the backend address, not the input order.
+	reversed := make([]*loadbalancer.LegacyBackend, len(backends))
+	for i, be := range backends {
+		reversed[len(backends)-1-i] = be
+	}
+	assert.Equal(t, capped, syncer.capBackends(serviceName, "8080", reversed))
+}
+
+func TestGetEndpointsForLBBackendsCustomClusterName(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"12000": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 12000, 3),
+			},
+		},
+		"*": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 15000, 3),
+			},
+		},
+	}
+
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig,
+		func(serviceName loadbalancer.ServiceName, port string) string {
+			return serviceName.Namespace + "_" + serviceName.Name + "_" + port
+		})
+
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 3)
+
+	var allClusterNames []string
+	for _, ep := range endpoints {
+		allClusterNames = append(allClusterNames, ep.GetClusterName())
+	}
+
+	assert.Contains(t, allClusterNames, "test-ns_test-name_12000")
+	assert.Contains(t, allClusterNames, "test-ns_test-name_*")
+	// The backward-compat anyPort cluster keeps the plain service name.
+	assert.Contains(t, allClusterNames, "test-ns/test-name")
+}
+
+func TestGetEndpointsForLBBackendsAnyPortCompatCluster(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"*": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 15000, 3),
+			},
+		},
+	}
+
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	require.True(t, syncer.emitAnyPortCompatCluster)
+
+	clusterNames := func() []string {
+		var names []string
+		for _, ep := range syncer.getEndpointsForLBBackends(serviceName, backends) {
+			names = append(names, ep.GetClusterName())
+		}
+		return names
+	}
+
+	// Default: both the port-qualified and the bare service-name cluster.
+	names := clusterNames()
+	require.Len(t, names, 2)
+	assert.Contains(t, names, "test-ns/test-name:*")
+	assert.Contains(t, names, "test-ns/test-name")
+
+	// With the compat cluster disabled only the port-qualified one remains.
+	cfg := defaultSyncerConfig
+	cfg.EmitAnyPortCompatCluster = false
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	names = clusterNames()
+	require.Len(t, names, 1)
+	assert.Contains(t, names, "test-ns/test-name:*")
+}
+
+// fakeXDSServer records upserted resources; every other XDSServer method
+// panics via the embedded nil interface.
+type fakeXDSServer struct {
+	envoy.XDSServer
+	upserted []envoy.Resources
+}
+
+func (f *fakeXDSServer) UpsertEnvoyResources(_ context.Context, resources envoy.Resources) error {
+	f.upserted = append(f.upserted, resources)
+	return nil
+}
+
+func TestSyncStrictBackendMode(t *testing.T) {
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	// No backends at all: filtering leaves an empty set.
+	svc := &loadbalancer.LegacySVC{
+		Name: serviceName,
+		Frontend: loadbalancer.L3n4AddrID{
+			L3n4Addr: loadbalancer.L3n4Addr{
+				L4Addr: loadbalancer.L4Addr{
+					Port: 8080,
+				},
+			},
+		},
+	}
+
+	xds := &fakeXDSServer{}
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), xds, defaultSyncerConfig, nil)
+	syncer.RegisterServiceUsageInCEC(serviceName, service.L7LBResourceName{Namespace: "test-ns", Name: "test-cec"}, []string{"8080"})
+
+	// Permissive (default): zero backends sync without error.
+	require.NoError(t, syncer.Sync(svc))
+	require.Len(t, xds.upserted, 1)
+
+	// Strict: the empty set is still synced, but surfaced as an error.
+	cfg := defaultSyncerConfig
+	cfg.StrictBackendSync = true
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), xds, cfg, nil)
+	syncer.RegisterServiceUsageInCEC(serviceName, service.L7LBResourceName{Namespace: "test-ns", Name: "test-cec"}, []string{"8080"})
+	err := syncer.Sync(svc)
+	require.ErrorIs(t, err, ErrNoServiceBackends)
+	require.Len(t, xds.upserted, 2)
+
+	// With a backend present, strict mode stays quiet.
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+	svc.Backends = []*loadbalancer.LegacyBackend{
+		{
+			FEPortName: "http",
+			L3n4Addr:   *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 8080, 3),
+		},
+	}
+	svc.Frontend.L3n4Addr.L4Addr.Port = 8080
+	require.NoError(t, syncer.Sync(svc))
+}
+
+func TestAllRegisteredPorts(t *testing.T) {
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+
+	// Nothing registered yet.
+	assert.Empty(t, syncer.AllRegisteredPorts())
+
+	svcA := loadbalancer.ServiceName{Namespace: "test-ns", Name: "svc-a"}
+	svcB := loadbalancer.ServiceName{Namespace: "test-ns", Name: "svc-b"}
+	syncer.RegisterServiceUsageInCEC(svcA, service.L7LBResourceName{Namespace: "test-ns", Name: "cec-a"}, []string{"8080", "9090"})
+	syncer.RegisterServiceUsageInCEC(svcB, service.L7LBResourceName{Namespace: "test-ns", Name: "cec-b"}, []string{"9090", "443"})
+
+	// Overlapping ports collapse into a sorted unique union.
+	assert.Equal(t, []string{"443", "8080", "9090"}, syncer.AllRegisteredPorts())
+
+	// Deregistering drops the ports only that service contributed.
+	syncer.DeregisterServiceUsageInCEC(svcA, service.L7LBResourceName{Namespace: "test-ns", Name: "cec-a"})
+	assert.Equal(t, []string{"443", "9090"}, syncer.AllRegisteredPorts())
+}
+
+func TestBackendStartupGrace(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"12000": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 12000, 3),
+			},
+		},
+	}
+
+	cfg := defaultSyncerConfig
+	cfg.BackendGracePeriod = 10 * time.Second
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	now := time.Now()
+	syncer.now = func() time.Time { return now }
+
+	// A backend seen for the first time is within the grace period and is
+	// emitted as DEGRADED.
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	require.Len(t, endpoints[0].GetEndpoints(), 1)
+	assert.Equal(t, envoy_config_core.HealthStatus_DEGRADED,
+		endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetHealthStatus())
+
+	// Still degraded just before the grace boundary.
+	now = now.Add(9 * time.Second)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	assert.Equal(t, envoy_config_core.HealthStatus_DEGRADED,
+		endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetHealthStatus())
+
+	// Once the grace period has elapsed, the health status reverts to the
+	// default.
+	now = now.Add(2 * time.Second)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	assert.Equal(t, envoy_config_core.HealthStatus_UNKNOWN,
+		endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetHealthStatus())
+
+	// With no grace period configured (the default), fresh backends are
+	// emitted without a degraded status.
+	plainSyncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	endpoints = plainSyncer.getEndpointsForLBBackends(serviceName, backends)
+	assert.Equal(t, envoy_config_core.HealthStatus_UNKNOWN,
+		endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetHealthStatus())
+}
+
+func TestBackendStartupGracePruning(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	backend := &loadbalancer.LegacyBackend{
+		FEPortName: "http",
+		L3n4Addr:   *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 8080, 3),
+	}
+	svc := &loadbalancer.LegacySVC{
+		Name: serviceName,
+		Frontend: loadbalancer.L3n4AddrID{
+			L3n4Addr: loadbalancer.L3n4Addr{
+				L4Addr: loadbalancer.L4Addr{
+					Port: 8080,
+				},
+			},
+		},
+		Backends: []*loadbalancer.LegacyBackend{backend},
+	}
+
+	cfg := defaultSyncerConfig
+	cfg.BackendGracePeriod = 10 * time.Second
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), &fakeXDSServer{}, cfg, nil)
+	syncer.RegisterServiceUsageInCEC(serviceName, service.L7LBResourceName{Namespace: "test-ns", Name: "test-cec"}, []string{"8080"})
+	now := time.Now()
+	syncer.now = func() time.Time { return now }
+
+	// Syncing the backend records its first-seen timestamp.
+	require.NoError(t, syncer.Sync(svc))
+	require.Len(t, syncer.backendFirstSeen[serviceName], 1)
+
+	// Once the backend disappears from the service, its entry is pruned so
+	// the map does not grow under churn.
+	svc.Backends = nil
+	require.NoError(t, syncer.Sync(svc))
+	assert.Empty(t, syncer.backendFirstSeen)
+
+	// When the backend returns after its grace period would long have
+	// elapsed, it goes through the grace period again instead of reusing the
+	// stale timestamp.
+	now = now.Add(time.Hour)
+	svc.Backends = []*loadbalancer.LegacyBackend{backend}
+	require.NoError(t, syncer.Sync(svc))
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, map[string][]*loadbalancer.LegacyBackend{
+		"8080": {backend},
+	})
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, envoy_config_core.HealthStatus_DEGRADED,
+		endpoints[0].GetEndpoints()[0].GetLbEndpoints()[0].GetHealthStatus())
+
+	// Deregistering the service drops its remaining state.
+	syncer.DeregisterServiceUsageInCEC(serviceName, service.L7LBResourceName{Namespace: "test-ns", Name: "test-cec"})
+	assert.Empty(t, syncer.backendFirstSeen)
+}
+
+func TestGetEndpointsForLBBackendsPerProtocolClusters(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	// A dual-protocol port: TCP and UDP backends behind the same port.
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"12000": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 12000, 3),
+			},
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.UDP, types.AddrClusterFrom(testAddr, 0), 12000, 4),
+			},
+		},
+	}
+
+	// Default: UDP backends are filtered out and a single cluster remains.
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "test-ns/test-name:12000", endpoints[0].GetClusterName())
+	assert.Len(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints(), 1)
+
+	// With per-protocol clusters enabled, each protocol gets its own
+	// cluster carrying only its backends.
+	cfg := defaultSyncerConfig
+	cfg.EmitPerProtocolClusters = true
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 2)
+	byName := map[string]int{}
+	for _, ep := range endpoints {
+		byName[ep.GetClusterName()] = len(ep.GetEndpoints()[0].GetLbEndpoints())
+	}
+	assert.Equal(t, map[string]int{
+		"test-ns/test-name:12000:tcp": 1,
+		"test-ns/test-name:12000:udp": 1,
+	}, byName)
+}
+
+func TestGetEndpointsForLBBackendsPerProtocolAnyPortCompat(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	// An any-port service with a dual-protocol backend set.
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		anyPort: {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 12000, 3),
+			},
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.UDP, types.AddrClusterFrom(testAddr, 0), 12000, 4),
+			},
+		},
+	}
+
+	// Per-protocol clusters and the any-port compat cluster (both enabled)
+	// combine: the protocol-suffixed clusters are emitted alongside the
+	// bare-service compat cluster, which keeps its TCP-only contents.
+	cfg := defaultSyncerConfig
+	cfg.EmitPerProtocolClusters = true
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	byName := map[string]int{}
+	for _, ep := range endpoints {
+		byName[ep.GetClusterName()] = len(ep.GetEndpoints()[0].GetLbEndpoints())
+	}
+	assert.Equal(t, map[string]int{
+		"test-ns/test-name:*:tcp": 1,
+		"test-ns/test-name:*:udp": 1,
+		"test-ns/test-name":       1,
+	}, byName)
+
+	// With the compat cluster disabled, only the protocol-suffixed clusters
+	// remain.
+	cfg.EmitAnyPortCompatCluster = false
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 2)
+	for _, ep := range endpoints {
+		assert.NotEqual(t, "test-ns/test-name", ep.GetClusterName())
+	}
+}
+
+func TestGetEndpointsForLBBackendsLocalityWeights(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	mkBackend := func(port uint16, zone uint8) *loadbalancer.LegacyBackend {
+		return &loadbalancer.LegacyBackend{
+			ZoneID:   zone,
+			L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), port, 3),
+		}
+	}
+	// Zone 1 holds two backends, zone 2 one.
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"12000": {
+			mkBackend(12000, 1),
+			mkBackend(12001, 1),
+			mkBackend(12002, 2),
+		},
+	}
+
+	// Default: a single unweighted locality group.
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	endpoints := syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	require.Len(t, endpoints[0].GetEndpoints(), 1)
+	assert.Nil(t, endpoints[0].GetEndpoints()[0].GetLoadBalancingWeight())
+	assert.Len(t, endpoints[0].GetEndpoints()[0].GetLbEndpoints(), 3)
+
+	// With locality weights, each zone gets its own group weighted by its
+	// backend count, in zone order.
+	cfg := defaultSyncerConfig
+	cfg.EmitLocalityWeights = true
+	syncer = newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, cfg, nil)
+	endpoints = syncer.getEndpointsForLBBackends(serviceName, backends)
+	require.Len(t, endpoints, 1)
+	groups := endpoints[0].GetEndpoints()
+	require.Len(t, groups, 2)
+	assert.Equal(t, uint32(2), groups[0].GetLoadBalancingWeight().GetValue())
+	assert.Len(t, groups[0].GetLbEndpoints(), 2)
+	assert.Equal(t, uint32(1), groups[1].GetLoadBalancingWeight().GetValue())
+	assert.Len(t, groups[1].GetLbEndpoints(), 1)
+}
+
+func TestGetEndpointsForLBBackendsPortRemap(t *testing.T) {
+	testAddr, err := netip.ParseAddr("192.128.1.1")
+	require.NoError(t, err)
+
+	serviceName := loadbalancer.ServiceName{
+		Namespace: "test-ns",
+		Name:      "test-name",
+	}
+	backends := map[string][]*loadbalancer.LegacyBackend{
+		"8080": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 8080, 3),
+			},
+		},
+		"9090": {
+			{
+				L3n4Addr: *loadbalancer.NewL3n4Addr(loadbalancer.TCP, types.AddrClusterFrom(testAddr, 0), 9090, 3),
+			},
+		},
+	}
+
+	emittedPorts := func(endpoints []*endpointv3.ClusterLoadAssignment) map[string]uint32 {
+		ports := map[string]uint32{}
+		for _, ep := range endpoints {
+			lbEndpoints := ep.GetEndpoints()[0].GetLbEndpoints()
+			ports[ep.GetClusterName()] = lbEndpoints[0].GetHostIdentifier().(*endpointv3.LbEndpoint_Endpoint).Endpoint.Address.GetAddress().(*envoy_config_core.Address_SocketAddress).SocketAddress.GetPortValue()
+		}
+		return ports
+	}
+
+	// Without a registered remap the backend's own port is emitted.
+	syncer := newEnvoyServiceBackendSyncer(hivetest.Logger(t), nil, defaultSyncerConfig, nil)
+	ports := emittedPorts(syncer.getEndpointsForLBBackends(serviceName, backends))
+	assert.Equal(t, map[string]uint32{
+		"test-ns/test-name:8080": 8080,
+		"test-ns/test-name:9090": 9090,
+	}, ports)
+
+	// A CEC-declared remap replaces the port for its frontend port only.
+	resourceName := service.L7LBResourceName{Namespace: "test-ns", Name: "test-cec"}
+	syncer.RegisterServiceUsageInCEC(serviceName, resourceName, []string{"8080", "9090"})
+	syncer.RegisterPortRemapInCEC(serviceName, resourceName, map[string]uint16{"8080": 18080})
+	ports = emittedPorts(syncer.getEndpointsForLBBackends(serviceName, backends))
+	assert.Equal(t, map[string]uint32{
+		"test-ns/test-name:8080": 18080,
+		"test-ns/test-name:9090": 9090,
+	}, ports)
+
+	// Re-registering the CEC resets its remaps.
+	syncer.RegisterServiceUsageInCEC(serviceName, resourceName, []string{"8080", "9090"})
+	ports = emittedPorts(syncer.getEndpointsForLBBackends(serviceName, backends))
+	assert.Equal(t, uint32(8080), ports["test-ns/test-name:8080"])
+}
@@ -76,9 +76,16 @@ type CiliumClusterwideNetworkPolicyList struct {
 	Items []CiliumClusterwideNetworkPolicy `json:"items"`
 }
 
+// DirectionCoverage reports whether the CCNP contains ingress and/or egress
+// rules, across both Spec and Specs, without fully parsing the policy.
+func (r *CiliumClusterwideNetworkPolicy) DirectionCoverage() (hasIngress, hasEgress bool) {
+	cnp := CiliumNetworkPolicy{Spec: r.Spec, Specs: r.Specs}
+	return cnp.DirectionCoverage()
+}
+
 // Parse parses a CiliumClusterwideNetworkPolicy and returns a list of cilium
-// policy rules.
-func (r *CiliumClusterwideNetworkPolicy) Parse(logger *slog.Logger, clusterName string) (api.Rules, error) {
+// policy rules. Rules using a feature named in disabledFeatures are rejected.
+func (r *CiliumClusterwideNetworkPolicy) Parse(logger *slog.Logger, clusterName string, disabledFeatures ...FeatureGate) (api.Rules, error) {
 	if r.ObjectMeta.Name == "" {
 		return nil, NewErrParse("CiliumClusterwideNetworkPolicy must have name")
 	}
@@ -109,5 +116,9 @@ func (r *CiliumClusterwideNetworkPolicy) Parse(logger *slog.Logger, clusterName
 		}
 	}
 
+	if err := checkDisabledFeatures(retRules, disabledFeatures); err != nil {
+		return nil, err
+	}
+
 	return retRules, nil
 }
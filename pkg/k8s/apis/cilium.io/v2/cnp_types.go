@@ -4,12 +4,22 @@
 package v2
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apivalidation "k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
 
+	"github.com/cilium/cilium/pkg/annotation"
 	"github.com/cilium/cilium/pkg/comparator"
 	k8sCiliumUtils "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/utils"
 	slimv1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/apis/meta/v1"
@@ -53,6 +63,19 @@ func (in *CiliumNetworkPolicy) DeepEqual(other *CiliumNetworkPolicy) bool {
 	return objectMetaDeepEqual(in.ObjectMeta, other.ObjectMeta) && in.deepEqual(other)
 }
 
+// SpecEqual compares only the Spec and Specs of 2 CNPs, along with their name
+// and namespace. Unlike DeepEqual it ignores all annotations and the Status
+// field entirely, so it does not report a change for status-only or
+// annotation-only updates.
+func (in *CiliumNetworkPolicy) SpecEqual(other *CiliumNetworkPolicy) bool {
+	if other == nil {
+		return false
+	}
+	return in.Name == other.Name &&
+		in.Namespace == other.Namespace &&
+		in.deepEqual(other)
+}
+
 // objectMetaDeepEqual performs an equality check for metav1.ObjectMeta that
 // ignores the LastAppliedConfigAnnotation. This function's usage is shared
 // among CNP and CCNP as they have the same structure.
@@ -85,6 +108,40 @@ type CiliumNetworkPolicyStatus struct {
 	Conditions []NetworkPolicyCondition `json:"conditions,omitempty"`
 }
 
+// StatusEqual compares two statuses while ignoring the LastUpdated and
+// LastTransitionTime timestamps, so that controllers can tell purely
+// timestamp-bumping status writes apart from real status changes and skip
+// re-reconciling on the former.
+func (a CiliumNetworkPolicyStatus) StatusEqual(b CiliumNetworkPolicyStatus) bool {
+	if len(a.DerivativePolicies) != len(b.DerivativePolicies) {
+		return false
+	}
+	for node, as := range a.DerivativePolicies {
+		bs, ok := b.DerivativePolicies[node]
+		if !ok {
+			return false
+		}
+		as.LastUpdated = slimv1.Time{}
+		bs.LastUpdated = slimv1.Time{}
+		if !as.DeepEqual(&bs) {
+			return false
+		}
+	}
+
+	if len(a.Conditions) != len(b.Conditions) {
+		return false
+	}
+	for i := range a.Conditions {
+		ac, bc := a.Conditions[i], b.Conditions[i]
+		if ac.Type != bc.Type || ac.Status != bc.Status ||
+			ac.Reason != bc.Reason || ac.Message != bc.Message {
+			return false
+		}
+	}
+
+	return true
+}
+
 // +deepequal-gen=true
 
 // CiliumNetworkPolicyNodeStatus is the status of a Cilium policy rule for a
@@ -119,6 +176,16 @@ type CiliumNetworkPolicyNodeStatus struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// IsStale returns true if the status was last updated longer than maxAge
+// before now. A status that was never updated (zero LastUpdated) is always
+// considered stale.
+func (s CiliumNetworkPolicyNodeStatus) IsStale(maxAge time.Duration, now time.Time) bool {
+	if s.LastUpdated.IsZero() {
+		return true
+	}
+	return now.Sub(s.LastUpdated.Time) > maxAge
+}
+
 // CreateCNPNodeStatus returns a CiliumNetworkPolicyNodeStatus created from the
 // provided fields.
 func CreateCNPNodeStatus(enforcing, ok bool, cnpError error, rev uint64, annotations map[string]string) CiliumNetworkPolicyNodeStatus {
@@ -158,12 +225,119 @@ func (r *CiliumNetworkPolicy) SetDerivedPolicyStatus(derivativePolicyName string
 	r.Status.DerivativePolicies[derivativePolicyName] = status
 }
 
+// UpdateDerivedPolicyStatus applies mutate to the derivative policy status
+// for the given derivative policy name and writes the result back. If no
+// entry exists yet, mutate receives a zero status. This avoids the
+// read-modify-write boilerplate of touching a single field, e.g. flipping
+// Enforcing or recording an error, while preserving the rest of the entry.
+func (r *CiliumNetworkPolicy) UpdateDerivedPolicyStatus(derivativePolicyName string, mutate func(*CiliumNetworkPolicyNodeStatus)) {
+	if r.Status.DerivativePolicies == nil {
+		r.Status.DerivativePolicies = map[string]CiliumNetworkPolicyNodeStatus{}
+	}
+	status := r.Status.DerivativePolicies[derivativePolicyName]
+	mutate(&status)
+	r.Status.DerivativePolicies[derivativePolicyName] = status
+}
+
+// FeatureGate names a policy feature that can be administratively disabled at
+// parse time. Rules making use of a disabled feature are rejected instead of
+// being applied by the agent.
+//
+// +k8s:deepcopy-gen=false
+// +deepequal-gen=false
+type FeatureGate struct {
+	// Name identifies the gated feature in the returned parse error.
+	Name string
+
+	// UsedBy reports whether the given rule makes use of the gated feature.
+	UsedBy func(*api.Rule) bool
+}
+
+// checkDisabledFeatures returns a parse error naming the first disabled
+// feature used by any of the given rules.
+func checkDisabledFeatures(rules api.Rules, disabledFeatures []FeatureGate) error {
+	for _, gate := range disabledFeatures {
+		if gate.UsedBy == nil {
+			continue
+		}
+		for _, rule := range rules {
+			if gate.UsedBy(rule) {
+				return NewErrParse(fmt.Sprintf("Invalid CiliumNetworkPolicy spec: feature %q is disabled", gate.Name))
+			}
+		}
+	}
+	return nil
+}
+
+// IsEffectivelyClusterwide returns true when Parse will treat this policy as
+// a CiliumClusterwideNetworkPolicy because its namespace is empty (the #12834
+// workaround). Tooling can use this to warn about namespaced CNPs that were
+// authored with cluster-scoped intent.
+func (r *CiliumNetworkPolicy) IsEffectivelyClusterwide() bool {
+	return k8sUtils.ExtractNamespace(&r.ObjectMeta) == ""
+}
+
+// IsHostPolicy returns true when this policy targets the host rather than pod
+// endpoints: either a rule carries a node selector (only valid on
+// CiliumClusterwideNetworkPolicies), or an endpoint selector explicitly
+// selects the reserved:host entity. Tooling can use this to classify policies
+// without a full Parse.
+func (r *CiliumNetworkPolicy) IsHostPolicy() bool {
+	rules := r.Specs
+	if r.Spec != nil {
+		rules = append(api.Rules{r.Spec}, rules...)
+	}
+
+	hostKey := labels.LabelSourceReservedKeyPrefix + labels.IDNameHost
+	for _, rule := range rules {
+		if rule.NodeSelector.LabelSelector != nil {
+			return true
+		}
+		if rule.EndpointSelector.LabelSelector != nil && rule.EndpointSelector.HasKey(hostKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSelectors checks that every endpoint and node selector in Spec and
+// Specs converts to a valid label selector, without running the full
+// Sanitize pass. It returns an aggregated error naming each bad selector.
+// This is considerably cheaper than Parse, making it suitable for fast
+// linting passes such as pre-commit hooks.
+func (r *CiliumNetworkPolicy) ValidateSelectors() error {
+	rules := r.Specs
+	if r.Spec != nil {
+		rules = append(api.Rules{r.Spec}, rules...)
+	}
+
+	var errs []error
+	check := func(ruleIdx int, field string, sel api.EndpointSelector) {
+		if sel.LabelSelector == nil {
+			return
+		}
+		if _, err := slimv1.LabelSelectorAsSelector(sel.LabelSelector); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: invalid %s %s: %w", ruleIdx, field, sel.LabelSelectorString(), err))
+		}
+	}
+	for i, rule := range rules {
+		check(i, "endpointSelector", rule.EndpointSelector)
+		check(i, "nodeSelector", rule.NodeSelector)
+	}
+	return errors.Join(errs...)
+}
+
 // Parse parses a CiliumNetworkPolicy and returns a list of cilium policy
-// rules.
-func (r *CiliumNetworkPolicy) Parse(logger *slog.Logger, clusterName string) (api.Rules, error) {
+// rules. Rules using a feature named in disabledFeatures are rejected.
+func (r *CiliumNetworkPolicy) Parse(logger *slog.Logger, clusterName string, disabledFeatures ...FeatureGate) (api.Rules, error) {
 	if r.ObjectMeta.Name == "" {
 		return nil, NewErrParse("CiliumNetworkPolicy must have name")
 	}
+	// Catch invalid generated names before they fail at the API server or in
+	// derivative policy naming.
+	if errs := apivalidation.IsDNS1123Subdomain(r.ObjectMeta.Name); len(errs) > 0 {
+		return nil, NewErrParse(fmt.Sprintf("CiliumNetworkPolicy name %q is not a valid DNS subdomain: %s", r.ObjectMeta.Name, strings.Join(errs, ", ")))
+	}
 
 	namespace := k8sUtils.ExtractNamespace(&r.ObjectMeta)
 	// Temporary fix for CCNPs. See #12834.
@@ -177,7 +351,7 @@ func (r *CiliumNetworkPolicy) Parse(logger *slog.Logger, clusterName string) (ap
 			Specs:      r.Specs,
 			Status:     r.Status,
 		}
-		return ccnp.Parse(logger, clusterName)
+		return ccnp.Parse(logger, clusterName, disabledFeatures...)
 	}
 	name := r.ObjectMeta.Name
 	uid := r.ObjectMeta.UID
@@ -208,9 +382,138 @@ func (r *CiliumNetworkPolicy) Parse(logger *slog.Logger, clusterName string) (ap
 		}
 	}
 
+	if err := checkDisabledFeatures(retRules, disabledFeatures); err != nil {
+		return nil, err
+	}
+
 	return retRules, nil
 }
 
+// SanitizeDiagnostic records the effect Sanitize had on a single rule during
+// ParseWithDiagnostics. Rule indexes the policy's rules, Spec first and Specs
+// after it. For normalized rules, Before and After hold the JSON rendering of
+// the rule before and after sanitization. A non-empty Error means Parse would
+// reject the rule.
+type SanitizeDiagnostic struct {
+	Rule       int    `json:"rule"`
+	Normalized bool   `json:"normalized,omitempty"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ParseWithDiagnostics parses like Parse but, instead of failing on the first
+// rule Sanitize rejects, records a per-rule diagnostic and continues. Rules
+// that sanitize cleanly are returned, alongside one diagnostic for every rule
+// that was normalized or would be rejected. This lets policy authors see the
+// transformations Cilium applies without committing the policy. The receiver
+// is not mutated.
+func (r *CiliumNetworkPolicy) ParseWithDiagnostics(logger *slog.Logger, clusterName string, disabledFeatures ...FeatureGate) (api.Rules, []SanitizeDiagnostic, error) {
+	if r.ObjectMeta.Name == "" {
+		return nil, nil, NewErrParse("CiliumNetworkPolicy must have name")
+	}
+	// Same name validation as Parse.
+	if errs := apivalidation.IsDNS1123Subdomain(r.ObjectMeta.Name); len(errs) > 0 {
+		return nil, nil, NewErrParse(fmt.Sprintf("CiliumNetworkPolicy name %q is not a valid DNS subdomain: %s", r.ObjectMeta.Name, strings.Join(errs, ", ")))
+	}
+
+	namespace := k8sUtils.ExtractNamespace(&r.ObjectMeta)
+	name := r.ObjectMeta.Name
+	uid := r.ObjectMeta.UID
+
+	if r.Spec == nil && r.Specs == nil {
+		// As in Parse's redirect for #12834, an empty namespace means this is
+		// a CCNP converted to a SlimCNP, so report the clusterwide error. The
+		// clusterwide semantics of the rules themselves - NodeSelector being
+		// permitted and the empty namespace passed through - are handled below.
+		if namespace == "" {
+			return nil, nil, ErrEmptyCCNP
+		}
+		return nil, nil, ErrEmptyCNP
+	}
+
+	var rules []*api.Rule
+	if r.Spec != nil {
+		rules = append(rules, r.Spec)
+	}
+	rules = append(rules, r.Specs...)
+
+	retRules := api.Rules{}
+	var diags []SanitizeDiagnostic
+	for i, rule := range rules {
+		before, err := json.Marshal(rule)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal rule %d: %w", i, err)
+		}
+		sanitized := rule.DeepCopy()
+		if err := sanitized.Sanitize(); err != nil {
+			diags = append(diags, SanitizeDiagnostic{Rule: i, Error: err.Error()})
+			continue
+		}
+		if namespace != "" && sanitized.NodeSelector.LabelSelector != nil {
+			diags = append(diags, SanitizeDiagnostic{Rule: i, Error: "rule cannot have NodeSelector"})
+			continue
+		}
+		after, err := json.Marshal(sanitized)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal sanitized rule %d: %w", i, err)
+		}
+		if !bytes.Equal(before, after) {
+			diags = append(diags, SanitizeDiagnostic{
+				Rule:       i,
+				Normalized: true,
+				Before:     string(before),
+				After:      string(after),
+			})
+		}
+		retRules = append(retRules, k8sCiliumUtils.ParseToCiliumRule(logger, clusterName, namespace, name, uid, sanitized))
+	}
+
+	if err := checkDisabledFeatures(retRules, disabledFeatures); err != nil {
+		return nil, nil, err
+	}
+
+	return retRules, diags, nil
+}
+
+// RenderEffectiveRules parses the CNP and renders the resulting sanitized
+// rules - with defaults and derived selectors filled in - as indented YAML.
+// This shows what Cilium will actually enforce, as opposed to what was
+// authored, which is useful in support cases.
+func (r *CiliumNetworkPolicy) RenderEffectiveRules(logger *slog.Logger, clusterName string) (string, error) {
+	rules, err := r.Parse(logger, clusterName)
+	if err != nil {
+		return "", err
+	}
+	out, err := yaml.Marshal(rules)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rules to YAML: %w", err)
+	}
+	return string(out), nil
+}
+
+// DefaultPolicyPriority is the priority assumed for policies that do not
+// declare one, placed in the middle of the range so annotated policies can
+// sort both before and after unannotated ones.
+const DefaultPolicyPriority = 1000
+
+// EffectivePriority returns the numeric priority the policy declares via the
+// annotation.PolicyPriority annotation, or DefaultPolicyPriority when the
+// annotation is absent or malformed. Lower values sort first. The priority
+// has no effect on enforcement; it exists for external tooling that needs a
+// deterministic policy order.
+func (r *CiliumNetworkPolicy) EffectivePriority() int {
+	raw, ok := r.ObjectMeta.Annotations[annotation.PolicyPriority]
+	if !ok {
+		return DefaultPolicyPriority
+	}
+	priority, err := strconv.Atoi(raw)
+	if err != nil {
+		return DefaultPolicyPriority
+	}
+	return priority
+}
+
 // GetIdentityLabels returns all rule labels in the CiliumNetworkPolicy.
 func (r *CiliumNetworkPolicy) GetIdentityLabels() labels.LabelArray {
 	namespace := k8sUtils.ExtractNamespace(&r.ObjectMeta)
@@ -227,6 +530,25 @@ func (r *CiliumNetworkPolicy) GetIdentityLabels() labels.LabelArray {
 	return k8sCiliumUtils.GetPolicyLabels(namespace, name, uid, derivedFrom)
 }
 
+// DirectionCoverage reports whether the CNP contains ingress and/or egress
+// rules, across both Spec and Specs, without fully parsing the policy. Deny
+// rules count towards their respective direction.
+func (r *CiliumNetworkPolicy) DirectionCoverage() (hasIngress, hasEgress bool) {
+	rules := r.Specs
+	if r.Spec != nil {
+		rules = append(api.Rules{r.Spec}, rules...)
+	}
+	for _, rule := range rules {
+		if len(rule.Ingress) > 0 || len(rule.IngressDeny) > 0 {
+			hasIngress = true
+		}
+		if len(rule.Egress) > 0 || len(rule.EgressDeny) > 0 {
+			hasEgress = true
+		}
+	}
+	return hasIngress, hasEgress
+}
+
 // RequiresDerivative return true if the CNP has any rule that will create a new
 // derivative rule.
 func (r *CiliumNetworkPolicy) RequiresDerivative() bool {
@@ -245,6 +567,62 @@ func (r *CiliumNetworkPolicy) RequiresDerivative() bool {
 	return false
 }
 
+// RequiresDNSProxy return true if the CNP has any rule that depends on the
+// DNS proxy to be enforced: an egress rule with ToFQDNs selectors or DNS L7
+// rules.
+func (r *CiliumNetworkPolicy) RequiresDNSProxy() bool {
+	if r.Spec != nil {
+		if ruleRequiresDNSProxy(r.Spec) {
+			return true
+		}
+	}
+	if r.Specs != nil {
+		for _, rule := range r.Specs {
+			if ruleRequiresDNSProxy(rule) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EndpointSelectors returns the endpoint selectors targeted by the CNP,
+// aggregated across Spec and Specs and deduplicated. This supports impact
+// analysis ("which workloads does this policy affect?") without fully parsing
+// the policy.
+func (r *CiliumNetworkPolicy) EndpointSelectors() []api.EndpointSelector {
+	rules := r.Specs
+	if r.Spec != nil {
+		rules = append(api.Rules{r.Spec}, rules...)
+	}
+
+	var selectors []api.EndpointSelector
+	seen := map[string]struct{}{}
+	for _, rule := range rules {
+		key := rule.EndpointSelector.String()
+		if _, duplicate := seen[key]; duplicate {
+			continue
+		}
+		seen[key] = struct{}{}
+		selectors = append(selectors, rule.EndpointSelector)
+	}
+	return selectors
+}
+
+func ruleRequiresDNSProxy(rule *api.Rule) bool {
+	for _, egress := range rule.Egress {
+		if len(egress.ToFQDNs) > 0 {
+			return true
+		}
+		for _, port := range egress.ToPorts {
+			if port.Rules != nil && len(port.Rules.DNS) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=false
 // +deepequal-gen=false
@@ -258,6 +636,43 @@ type CiliumNetworkPolicyList struct {
 	Items []CiliumNetworkPolicy `json:"items"`
 }
 
+// ParseAll parses every policy in the list, aggregating the rules of the ones
+// that parse cleanly. Failures do not abort the parse: they are collected
+// into the returned map, keyed by the offending policy's name. This suits
+// bulk import flows where one bad policy must not block the rest.
+func (l *CiliumNetworkPolicyList) ParseAll(logger *slog.Logger, clusterName string) (api.Rules, map[string]error) {
+	retRules := api.Rules{}
+	errs := map[string]error{}
+	for i := range l.Items {
+		rules, err := l.Items[i].Parse(logger, clusterName)
+		if err != nil {
+			errs[l.Items[i].ObjectMeta.Name] = err
+			continue
+		}
+		retRules = append(retRules, rules...)
+	}
+	return retRules, errs
+}
+
+// Namespaces returns the sorted unique set of namespaces the policies in the
+// list live in. Clusterwide policies (empty namespace, see
+// IsEffectivelyClusterwide) contribute an empty string entry, which sorts
+// first. Handy for multi-tenant tooling that groups policies by tenant.
+func (l *CiliumNetworkPolicyList) Namespaces() []string {
+	seen := map[string]struct{}{}
+	namespaces := make([]string, 0, len(l.Items))
+	for i := range l.Items {
+		ns := k8sUtils.ExtractNamespace(&l.Items[i].ObjectMeta)
+		if _, ok := seen[ns]; ok {
+			continue
+		}
+		seen[ns] = struct{}{}
+		namespaces = append(namespaces, ns)
+	}
+	slices.Sort(namespaces)
+	return namespaces
+}
+
 type PolicyConditionType string
 
 const (
@@ -8,13 +8,16 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cilium/hive/hivetest"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/cilium/cilium/pkg/annotation"
 	eniTypes "github.com/cilium/cilium/pkg/aws/eni/types"
 	cmtypes "github.com/cilium/cilium/pkg/clustermesh/types"
 	k8sConst "github.com/cilium/cilium/pkg/k8s/apis/cilium.io"
@@ -530,6 +533,284 @@ func TestParseWithNodeSelector(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestParseWithDisabledFeatures(t *testing.T) {
+	logger := hivetest.Logger(t)
+
+	l7Gate := FeatureGate{
+		Name: "l7-rules",
+		UsedBy: func(r *api.Rule) bool {
+			for _, ingress := range r.Ingress {
+				for _, port := range ingress.ToPorts {
+					if port.Rules != nil {
+						return true
+					}
+				}
+			}
+			for _, egress := range r.Egress {
+				for _, port := range egress.ToPorts {
+					if port.Rules != nil {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	}
+
+	l7Policy := CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "l7-rule",
+			UID:       uuidRule,
+		},
+		Spec: &api.Rule{
+			EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+			Ingress: []api.IngressRule{
+				{
+					ToPorts: []api.PortRule{
+						{
+							Ports: []api.PortProtocol{{Port: "80", Protocol: "TCP"}},
+							Rules: &api.L7Rules{HTTP: []api.PortRuleHTTP{{Path: "/public", Method: "GET"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	l4Policy := CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "l4-rule",
+			UID:       uuidRule,
+		},
+		Spec: &api.Rule{
+			EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+			Ingress: []api.IngressRule{
+				{
+					ToPorts: []api.PortRule{
+						{
+							Ports: []api.PortProtocol{{Port: "80", Protocol: "TCP"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Without any gates, both policies parse.
+	_, err := l7Policy.Parse(logger, cmtypes.PolicyAnyCluster)
+	require.NoError(t, err)
+	_, err = l4Policy.Parse(logger, cmtypes.PolicyAnyCluster)
+	require.NoError(t, err)
+
+	// With the L7 gate, only the policy using L7 rules is rejected.
+	_, err = l7Policy.Parse(logger, cmtypes.PolicyAnyCluster, l7Gate)
+	require.ErrorContains(t, err, `feature "l7-rules" is disabled`)
+	require.ErrorIs(t, err, ParsingErr)
+	_, err = l4Policy.Parse(logger, cmtypes.PolicyAnyCluster, l7Gate)
+	require.NoError(t, err)
+}
+
+func TestDirectionCoverage(t *testing.T) {
+	ingressRule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+		Ingress: []api.IngressRule{
+			{
+				ToPorts: []api.PortRule{
+					{
+						Ports: []api.PortProtocol{{Port: "80", Protocol: "TCP"}},
+					},
+				},
+			},
+		},
+	}
+	egressRule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+		Egress: []api.EgressRule{
+			{
+				EgressCommonRule: api.EgressCommonRule{
+					ToCIDR: []api.CIDR{"10.0.0.1"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name       string
+		cnp        CiliumNetworkPolicy
+		hasIngress bool
+		hasEgress  bool
+	}{
+		{
+			name:       "ingress-only",
+			cnp:        CiliumNetworkPolicy{Spec: ingressRule},
+			hasIngress: true,
+		},
+		{
+			name:      "egress-only",
+			cnp:       CiliumNetworkPolicy{Spec: egressRule},
+			hasEgress: true,
+		},
+		{
+			name:       "both-via-specs",
+			cnp:        CiliumNetworkPolicy{Specs: api.Rules{ingressRule, egressRule}},
+			hasIngress: true,
+			hasEgress:  true,
+		},
+		{
+			name: "empty",
+			cnp:  CiliumNetworkPolicy{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hasIngress, hasEgress := tc.cnp.DirectionCoverage()
+			require.Equal(t, tc.hasIngress, hasIngress)
+			require.Equal(t, tc.hasEgress, hasEgress)
+
+			ccnp := CiliumClusterwideNetworkPolicy{Spec: tc.cnp.Spec, Specs: tc.cnp.Specs}
+			hasIngress, hasEgress = ccnp.DirectionCoverage()
+			require.Equal(t, tc.hasIngress, hasIngress)
+			require.Equal(t, tc.hasEgress, hasEgress)
+		})
+	}
+}
+
+func TestRequiresDNSProxy(t *testing.T) {
+	fqdnRule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+		Egress: []api.EgressRule{
+			{
+				ToFQDNs: api.FQDNSelectorSlice{
+					{MatchName: "cilium.io"},
+				},
+			},
+		},
+	}
+	dnsL7Rule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+		Egress: []api.EgressRule{
+			{
+				ToPorts: []api.PortRule{
+					{
+						Ports: []api.PortProtocol{{Port: "53", Protocol: "UDP"}},
+						Rules: &api.L7Rules{
+							DNS: []api.PortRuleDNS{{MatchPattern: "*.cilium.io"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	plainRule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+		Egress: []api.EgressRule{
+			{
+				EgressCommonRule: api.EgressCommonRule{
+					ToCIDR: []api.CIDR{"10.0.0.1"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		cnp      CiliumNetworkPolicy
+		expected bool
+	}{
+		{
+			name:     "to-fqdns",
+			cnp:      CiliumNetworkPolicy{Spec: fqdnRule},
+			expected: true,
+		},
+		{
+			name:     "dns-l7-via-specs",
+			cnp:      CiliumNetworkPolicy{Specs: api.Rules{plainRule, dnsL7Rule}},
+			expected: true,
+		},
+		{
+			name: "no-dns-rules",
+			cnp:  CiliumNetworkPolicy{Spec: plainRule},
+		},
+		{
+			name: "empty",
+			cnp:  CiliumNetworkPolicy{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.cnp.RequiresDNSProxy())
+		})
+	}
+}
+
+func TestSpecEqual(t *testing.T) {
+	rule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+	}
+	base := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "policy",
+		},
+		Spec: rule,
+	}
+
+	// Status-only differences are ignored.
+	statusOnly := base.DeepCopy()
+	statusOnly.Status = CiliumNetworkPolicyStatus{
+		DerivativePolicies: map[string]CiliumNetworkPolicyNodeStatus{
+			"derived": {OK: true},
+		},
+	}
+	require.True(t, base.SpecEqual(statusOnly))
+
+	// Annotation-only differences are ignored, including annotations that
+	// DeepEqual would consider.
+	annotated := base.DeepCopy()
+	annotated.Annotations = map[string]string{"team": "netpol"}
+	require.True(t, base.SpecEqual(annotated))
+	require.False(t, base.DeepEqual(annotated))
+
+	// Spec differences are detected.
+	changed := base.DeepCopy()
+	changed.Spec = &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=frontend")),
+	}
+	require.False(t, base.SpecEqual(changed))
+
+	// As are differing names and nil policies.
+	renamed := base.DeepCopy()
+	renamed.Name = "other"
+	require.False(t, base.SpecEqual(renamed))
+	require.False(t, base.SpecEqual(nil))
+}
+
+func TestCNPNodeStatusIsStale(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	maxAge := 5 * time.Minute
+
+	// Recently updated status is fresh
+	fresh := CiliumNetworkPolicyNodeStatus{
+		LastUpdated: slim_metav1.NewTime(now.Add(-time.Minute)),
+	}
+	require.False(t, fresh.IsStale(maxAge, now))
+
+	// Updated exactly maxAge ago is still fresh
+	boundary := CiliumNetworkPolicyNodeStatus{
+		LastUpdated: slim_metav1.NewTime(now.Add(-maxAge)),
+	}
+	require.False(t, boundary.IsStale(maxAge, now))
+
+	// Older than maxAge is stale
+	stale := CiliumNetworkPolicyNodeStatus{
+		LastUpdated: slim_metav1.NewTime(now.Add(-time.Hour)),
+	}
+	require.True(t, stale.IsStale(maxAge, now))
+
+	// Never updated is always stale
+	require.True(t, CiliumNetworkPolicyNodeStatus{}.IsStale(maxAge, now))
+}
+
 func TestCiliumNodeInstanceID(t *testing.T) {
 	require.Empty(t, (*CiliumNode)(nil).InstanceID())
 	require.Empty(t, (&CiliumNode{}).InstanceID())
@@ -700,3 +981,425 @@ func TestGetIP(t *testing.T) {
 	// Return a nil IP when no valid IPv6 addresses exist
 	require.Nil(t, ip)
 }
+
+func TestEndpointSelectors(t *testing.T) {
+	backendES := api.NewESFromLabels(labels.ParseSelectLabel("role=backend"))
+	frontendES := api.NewESFromLabels(labels.ParseSelectLabel("role=frontend"))
+
+	singleSpec := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "single"},
+		Spec:       &api.Rule{EndpointSelector: backendES},
+	}
+	selectors := singleSpec.EndpointSelectors()
+	require.Len(t, selectors, 1)
+	require.Equal(t, backendES.String(), selectors[0].String())
+
+	// Multi-spec policies aggregate across Spec and Specs, deduplicated.
+	multiSpec := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "multi"},
+		Spec:       &api.Rule{EndpointSelector: backendES},
+		Specs: api.Rules{
+			{EndpointSelector: frontendES},
+			{EndpointSelector: backendES},
+		},
+	}
+	selectors = multiSpec.EndpointSelectors()
+	require.Len(t, selectors, 2)
+
+	// The empty (match-all) selector is reported too.
+	matchAll := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "match-all"},
+		Spec:       &api.Rule{},
+	}
+	require.Len(t, matchAll.EndpointSelectors(), 1)
+
+	// A policy without rules has no selectors.
+	require.Empty(t, (&CiliumNetworkPolicy{}).EndpointSelectors())
+}
+
+func TestParseRejectsInvalidNames(t *testing.T) {
+	logger := hivetest.Logger(t)
+
+	newCNP := func(name string) *CiliumNetworkPolicy {
+		return &CiliumNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: &api.Rule{
+				EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+				Ingress: []api.IngressRule{
+					{
+						IngressCommonRule: api.IngressCommonRule{
+							FromEndpoints: []api.EndpointSelector{
+								api.NewESFromLabels(labels.ParseSelectLabel("role=frontend")),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	_, err := newCNP("valid-name.suffix").Parse(logger, cmtypes.PolicyAnyCluster)
+	require.NoError(t, err)
+
+	for _, name := range []string{
+		"UpperCase",
+		"under_score",
+		strings.Repeat("a", 254),
+	} {
+		_, err := newCNP(name).Parse(logger, cmtypes.PolicyAnyCluster)
+		require.Error(t, err, "name %q should be rejected", name)
+		require.ErrorContains(t, err, "not a valid DNS subdomain")
+	}
+}
+
+func TestParseWithDiagnostics(t *testing.T) {
+	logger := hivetest.Logger(t)
+
+	cnp := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "diagnose-me"},
+		Specs: api.Rules{
+			// Sanitize defaults the empty port protocol to ANY.
+			{
+				EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+				Ingress: []api.IngressRule{
+					{
+						ToPorts: []api.PortRule{
+							{Ports: []api.PortProtocol{{Port: "80"}}},
+						},
+					},
+				},
+			},
+			// A rule without any direction is rejected by Sanitize.
+			{
+				EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+			},
+		},
+	}
+
+	rules, diags, err := cnp.ParseWithDiagnostics(logger, cmtypes.PolicyAnyCluster)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Len(t, diags, 2)
+
+	require.True(t, diags[0].Normalized)
+	require.Equal(t, 0, diags[0].Rule)
+	require.NotContains(t, diags[0].Before, `"protocol":"ANY"`)
+	require.Contains(t, diags[0].After, `"protocol":"ANY"`)
+
+	require.Equal(t, 1, diags[1].Rule)
+	require.Contains(t, diags[1].Error, "at least one of Ingress")
+
+	// The receiver is untouched: a later Parse still rejects the bad rule.
+	_, err = cnp.Parse(logger, cmtypes.PolicyAnyCluster)
+	require.Error(t, err)
+
+	// The same name validation as Parse applies.
+	badName := cnp.DeepCopy()
+	badName.ObjectMeta.Name = "Upper_Case"
+	_, _, err = badName.ParseWithDiagnostics(logger, cmtypes.PolicyAnyCluster)
+	require.ErrorContains(t, err, "not a valid DNS subdomain")
+
+	// A CCNP converted to a SlimCNP (empty namespace) without any rules
+	// reports the clusterwide error, as Parse does.
+	clusterwide := &CiliumNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "clusterwide"}}
+	_, _, err = clusterwide.ParseWithDiagnostics(logger, cmtypes.PolicyAnyCluster)
+	require.ErrorIs(t, err, ErrEmptyCCNP)
+}
+
+func TestParseAll(t *testing.T) {
+	logger := hivetest.Logger(t)
+
+	validRule := &api.Rule{
+		EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+		Ingress: []api.IngressRule{
+			{
+				IngressCommonRule: api.IngressCommonRule{
+					FromEndpoints: []api.EndpointSelector{
+						api.NewESFromLabels(labels.ParseSelectLabel("role=frontend")),
+					},
+				},
+			},
+		},
+	}
+
+	list := &CiliumNetworkPolicyList{
+		Items: []CiliumNetworkPolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "good-a"},
+				Spec:       validRule,
+			},
+			{
+				// Missing both Spec and Specs.
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "empty"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "good-b"},
+				Specs:      api.Rules{validRule, validRule},
+			},
+			{
+				// A direction-less rule is rejected by Sanitize.
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bad-rule"},
+				Spec: &api.Rule{
+					EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+				},
+			},
+		},
+	}
+
+	rules, errs := list.ParseAll(logger, cmtypes.PolicyAnyCluster)
+	require.Len(t, rules, 3)
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs["empty"], ErrEmptyCNP)
+	require.ErrorContains(t, errs["bad-rule"], "at least one of Ingress")
+	require.NotContains(t, errs, "good-a")
+}
+
+func TestEffectivePriority(t *testing.T) {
+	newCNP := func(annotations map[string]string) *CiliumNetworkPolicy {
+		return &CiliumNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "prio", Annotations: annotations},
+		}
+	}
+
+	require.Equal(t, DefaultPolicyPriority, newCNP(nil).EffectivePriority())
+	require.Equal(t, 5, newCNP(map[string]string{annotation.PolicyPriority: "5"}).EffectivePriority())
+	require.Equal(t, -10, newCNP(map[string]string{annotation.PolicyPriority: "-10"}).EffectivePriority())
+	// Malformed values fall back to the default instead of failing.
+	require.Equal(t, DefaultPolicyPriority, newCNP(map[string]string{annotation.PolicyPriority: "high"}).EffectivePriority())
+}
+
+func TestIsEffectivelyClusterwide(t *testing.T) {
+	namespaced := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "namespaced"},
+	}
+	require.False(t, namespaced.IsEffectivelyClusterwide())
+
+	// With an empty namespace Parse redirects to the CCNP parser, and the
+	// helper exposes that up front.
+	clusterwide := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusterwide"},
+	}
+	require.True(t, clusterwide.IsEffectivelyClusterwide())
+}
+
+func TestRenderEffectiveRules(t *testing.T) {
+	logger := hivetest.Logger(t)
+
+	cnp := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "render-me"},
+		Spec: &api.Rule{
+			EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+			Ingress: []api.IngressRule{
+				{
+					IngressCommonRule: api.IngressCommonRule{
+						FromEndpoints: []api.EndpointSelector{
+							api.NewESFromLabels(labels.ParseSelectLabel("role=frontend")),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rendered, err := cnp.RenderEffectiveRules(logger, cmtypes.PolicyAnyCluster)
+	require.NoError(t, err)
+
+	// The rendered rules reflect the sanitized, parsed policy: the authored
+	// selector plus the namespace restriction and provenance labels that
+	// parsing fills in.
+	require.Contains(t, rendered, "role: backend")
+	require.Contains(t, rendered, "io.kubernetes.pod.namespace: ns")
+	require.Contains(t, rendered, "key: io.cilium.k8s.policy.name")
+	require.Contains(t, rendered, "value: render-me")
+	require.Contains(t, rendered, "enableDefaultDeny")
+
+	// Unparsable policies surface the parse error.
+	_, err = (&CiliumNetworkPolicy{}).RenderEffectiveRules(logger, cmtypes.PolicyAnyCluster)
+	require.Error(t, err)
+}
+
+func TestStatusEqual(t *testing.T) {
+	base := CiliumNetworkPolicyStatus{
+		DerivativePolicies: map[string]CiliumNetworkPolicyNodeStatus{
+			"node-1": {
+				OK:          true,
+				Enforcing:   true,
+				Revision:    7,
+				LastUpdated: slim_metav1.Time{Time: time.Unix(100, 0)},
+			},
+		},
+		Conditions: []NetworkPolicyCondition{
+			{
+				Type:               PolicyConditionValid,
+				Status:             "True",
+				LastTransitionTime: slim_metav1.Time{Time: time.Unix(100, 0)},
+			},
+		},
+	}
+
+	// Only timestamps differ: equal.
+	bumped := CiliumNetworkPolicyStatus{
+		DerivativePolicies: map[string]CiliumNetworkPolicyNodeStatus{
+			"node-1": {
+				OK:          true,
+				Enforcing:   true,
+				Revision:    7,
+				LastUpdated: slim_metav1.Time{Time: time.Unix(200, 0)},
+			},
+		},
+		Conditions: []NetworkPolicyCondition{
+			{
+				Type:               PolicyConditionValid,
+				Status:             "True",
+				LastTransitionTime: slim_metav1.Time{Time: time.Unix(200, 0)},
+			},
+		},
+	}
+	require.True(t, base.StatusEqual(bumped))
+	require.True(t, bumped.StatusEqual(base))
+
+	// A real node status change is detected.
+	broken := *bumped.DeepCopy()
+	ns := broken.DerivativePolicies["node-1"]
+	ns.OK = false
+	ns.Error = "failed to import"
+	broken.DerivativePolicies["node-1"] = ns
+	require.False(t, base.StatusEqual(broken))
+
+	// A condition status flip is detected.
+	flipped := *bumped.DeepCopy()
+	flipped.Conditions[0].Status = "False"
+	require.False(t, base.StatusEqual(flipped))
+
+	// Differing node sets are detected.
+	extra := *bumped.DeepCopy()
+	extra.DerivativePolicies["node-2"] = CiliumNetworkPolicyNodeStatus{OK: true}
+	require.False(t, base.StatusEqual(extra))
+}
+
+func TestUpdateDerivedPolicyStatus(t *testing.T) {
+	cnp := &CiliumNetworkPolicy{}
+	cnp.SetDerivedPolicyStatus("derived", CiliumNetworkPolicyNodeStatus{
+		OK:        true,
+		Enforcing: true,
+		Revision:  3,
+		Annotations: map[string]string{
+			"keep": "me",
+		},
+	})
+
+	// Flip just the error state; the other fields survive.
+	cnp.UpdateDerivedPolicyStatus("derived", func(status *CiliumNetworkPolicyNodeStatus) {
+		status.OK = false
+		status.Error = "failed to realize"
+	})
+
+	status := cnp.Status.DerivativePolicies["derived"]
+	require.False(t, status.OK)
+	require.Equal(t, "failed to realize", status.Error)
+	require.True(t, status.Enforcing)
+	require.Equal(t, uint64(3), status.Revision)
+	require.Equal(t, map[string]string{"keep": "me"}, status.Annotations)
+
+	// Unknown names start from a zero status, and a nil map is initialized.
+	fresh := &CiliumNetworkPolicy{}
+	fresh.UpdateDerivedPolicyStatus("new-entry", func(status *CiliumNetworkPolicyNodeStatus) {
+		status.Enforcing = true
+	})
+	require.True(t, fresh.Status.DerivativePolicies["new-entry"].Enforcing)
+	require.False(t, fresh.Status.DerivativePolicies["new-entry"].OK)
+}
+
+func TestIsHostPolicy(t *testing.T) {
+	// A clusterwide policy with a node selector targets the host.
+	ccnp := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "host-fw"},
+		Spec: &api.Rule{
+			NodeSelector: api.NewESFromLabels(labels.ParseSelectLabel("node-role=infra")),
+		},
+	}
+	require.True(t, ccnp.IsHostPolicy())
+
+	// So does one whose endpoint selector picks the reserved:host entity.
+	hostSelector := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "host-by-label"},
+		Specs: api.Rules{
+			{
+				EndpointSelector: api.NewESFromLabels(labels.NewLabel(labels.IDNameHost, "", labels.LabelSourceReserved)),
+			},
+		},
+	}
+	require.True(t, hostSelector.IsHostPolicy())
+
+	// A regular namespaced CNP selecting pods does not.
+	cnp := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-policy", Namespace: "ns"},
+		Spec: &api.Rule{
+			EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=frontend")),
+		},
+	}
+	require.False(t, cnp.IsHostPolicy())
+
+	require.False(t, (&CiliumNetworkPolicy{}).IsHostPolicy())
+}
+
+func TestValidateSelectors(t *testing.T) {
+	valid := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "valid", Namespace: "ns"},
+		Spec: &api.Rule{
+			EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=frontend")),
+		},
+	}
+	require.NoError(t, valid.ValidateSelectors())
+
+	// A malformed selector is named in the aggregated error, including which
+	// rule and field it came from.
+	invalid := &CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "ns"},
+		Specs: api.Rules{
+			{
+				EndpointSelector: api.NewESFromLabels(labels.ParseSelectLabel("role=backend")),
+			},
+			{
+				EndpointSelector: api.EndpointSelector{
+					LabelSelector: &slim_metav1.LabelSelector{
+						MatchLabels: map[string]slim_metav1.MatchLabelsValue{"app": "bad value!"},
+					},
+				},
+			},
+		},
+	}
+	err := invalid.ValidateSelectors()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rule 1")
+	require.Contains(t, err.Error(), "endpointSelector")
+
+	// Policies without any selectors validate trivially.
+	require.NoError(t, (&CiliumNetworkPolicy{}).ValidateSelectors())
+}
+
+func TestCiliumNetworkPolicyListNamespaces(t *testing.T) {
+	cnp := func(name, namespace string) CiliumNetworkPolicy {
+		return CiliumNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+	}
+
+	single := &CiliumNetworkPolicyList{
+		Items: []CiliumNetworkPolicy{cnp("a", "ns-1"), cnp("b", "ns-1")},
+	}
+	require.Equal(t, []string{"ns-1"}, single.Namespaces())
+
+	multi := &CiliumNetworkPolicyList{
+		Items: []CiliumNetworkPolicy{cnp("a", "ns-2"), cnp("b", "ns-1"), cnp("c", "ns-2")},
+	}
+	require.Equal(t, []string{"ns-1", "ns-2"}, multi.Namespaces())
+
+	// Clusterwide policies show up as the empty namespace, sorted first.
+	withClusterwide := &CiliumNetworkPolicyList{
+		Items: []CiliumNetworkPolicy{cnp("a", "ns-1"), cnp("host-fw", "")},
+	}
+	require.Equal(t, []string{"", "ns-1"}, withClusterwide.Namespaces())
+
+	require.Empty(t, (&CiliumNetworkPolicyList{}).Namespaces())
+}
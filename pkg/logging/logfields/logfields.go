@@ -857,6 +857,15 @@ const (
 	// ConfigAnnotation is an annotation on a node
 	ConfigAnnotation = "configAnnotation"
 
+	// ConfigSourcesProvided is the number of configuration sources given to a resolve
+	ConfigSourcesProvided = "sourcesProvided"
+
+	// ConfigSourcesUsed is the number of configuration sources that contributed keys
+	ConfigSourcesUsed = "sourcesUsed"
+
+	// ConfigKeysResolved is the number of configuration keys in a resolved configuration
+	ConfigKeysResolved = "keysResolved"
+
 	// User identifies a given user
 	User = "user"
 
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resolver
+
+import (
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+)
+
+type Metrics struct {
+	ConfigOverrides       metric.Vec[metric.Counter]
+	ConfigOverridesDenied metric.Vec[metric.Counter]
+}
+
+func MetricsProvider() *Metrics {
+	return &Metrics{
+		ConfigOverrides: metric.NewCounterVec(metric.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "config_overrides_total",
+			Help:      "Number of times a higher-priority config source overrode a key already set by a lower-priority one",
+		}, []string{metrics.LabelSource}),
+		ConfigOverridesDenied: metric.NewCounterVec(metric.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "config_overrides_denied_total",
+			Help:      "Number of config keys dropped because the allow/deny lists forbid overriding them",
+		}, []string{metrics.LabelSource}),
+	}
+}
@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resolver
+
+import (
+	"log/slog"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+)
+
+// ResolveOption customizes the behavior of ResolveConfigurations.
+type ResolveOption func(*resolveOptions)
+
+// CNCPriorityMode selects how matching CiliumNodeConfigs are prioritized when
+// more than one sets the same key.
+type CNCPriorityMode int
+
+const (
+	// CNCPriorityName orders matching CiliumNodeConfigs lexicographically by
+	// name, the last name winning. This is the default.
+	CNCPriorityName CNCPriorityMode = iota
+	// CNCPriorityCreation orders matching CiliumNodeConfigs by their
+	// CreationTimestamp, the newest winning, with ties broken by name.
+	CNCPriorityCreation
+)
+
+type resolveOptions struct {
+	normalizeKeys    bool
+	lowercaseKeys    bool
+	readConcurrency  int
+	defaults         map[string]string
+	immutableKeys    sets.Set[string]
+	transforms       map[string]func(string) string
+	cncPriority      CNCPriorityMode
+	overrideLogLevel slog.Level
+	metrics          *Metrics
+	httpClient       *http.Client
+	ignoreEmptyAll   bool
+	ignoreEmptyKeys  sets.Set[string]
+
+	requiredKeys      []string
+	requiredFallbacks map[string]string
+	schemaMigrations  map[string]map[string]string
+	unseal            func([]byte) (map[string]string, error)
+
+	deprecatedKeys        map[string]string
+	migrateDeprecatedKeys bool
+	nodeOverridePrefixes  []string
+	dynamicClient         dynamic.Interface
+}
+
+// ignoresEmpty reports whether an empty value for key must not override a
+// non-empty lower-priority one.
+func (o resolveOptions) ignoresEmpty(key string) bool {
+	return o.ignoreEmptyAll || o.ignoreEmptyKeys.Has(key)
+}
+
+// WithNormalizedKeys enables normalization of configuration keys as each
+// source is read: surrounding whitespace is trimmed and, if lowercase is set,
+// keys are lowercased. Keys that are invalid after normalization are logged
+// and dropped. Normalization is off by default.
+func WithNormalizedKeys(lowercase bool) ResolveOption {
+	return func(o *resolveOptions) {
+		o.normalizeKeys = true
+		o.lowercaseKeys = lowercase
+	}
+}
+
+// WithDefaults merges the given compiled-in defaults in as the lowest-priority
+// source. A synthetic ConfigSource of kind "default" is recorded in the
+// sources list when any default survives as the winning value for its key,
+// closing the provenance gap for keys no source sets.
+func WithDefaults(defaults map[string]string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.defaults = defaults
+	}
+}
+
+// WithUnsealFunc provides the decryption function used by sealed-config-map
+// sources: it receives the raw sealed blob and returns the decrypted
+// key-value pairs. Keeping the function caller-provided keeps KMS and crypto
+// dependencies out of this package. Resolving a sealed source without an
+// unseal function is an error.
+func WithUnsealFunc(unseal func([]byte) (map[string]string, error)) ResolveOption {
+	return func(o *resolveOptions) {
+		o.unseal = unseal
+	}
+}
+
+// WithDynamicClient provides the dynamic client used by crd-status sources
+// to read arbitrary CRD objects. The main Clientset does not carry one, and
+// only deployments using such sources need it. Resolving a crd-status source
+// without a dynamic client is an error.
+func WithDynamicClient(dyn dynamic.Interface) ResolveOption {
+	return func(o *resolveOptions) {
+		o.dynamicClient = dyn
+	}
+}
+
+// WithSchemaMigrations applies version-gated key renames to each source
+// before the merge: migrations maps a schema version, as declared by the
+// source through the SchemaVersionKey key, to a table of deprecated-to-new
+// key renames. Sources declaring a version absent from migrations are left
+// untouched; a table under the empty version applies to sources declaring
+// none. Each rename is logged. This eases migrating key names across
+// releases while old and new sources coexist.
+func WithSchemaMigrations(migrations map[string]map[string]string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.schemaMigrations = migrations
+	}
+}
+
+// WithNodeOverridePrefixes sets the ordered list of annotation prefixes a
+// node source reads K=V overrides from, instead of only the default
+// "config.cilium.io". Prefixes are read in order, later ones overriding
+// earlier ones, with conflicts logged. This supports layered ownership of
+// node overrides, e.g. platform defaults under one prefix and per-cluster
+// tweaks under another.
+func WithNodeOverridePrefixes(prefixes []string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.nodeOverridePrefixes = prefixes
+	}
+}
+
+// WithDeprecatedKeys registers keys deprecated upstream, each mapped to its
+// replacement key or to a free-form message pointing at what to use instead.
+// Any source still setting a deprecated key is warned about. When migrate is
+// set, replacements naming a key (anything without a space) additionally
+// receive the deprecated key's value, unless the source already sets the
+// replacement itself, in which case the deprecated key is dropped and the
+// replacement wins. Unlike WithSchemaMigrations this applies unconditionally,
+// regardless of any schema version a source declares.
+func WithDeprecatedKeys(deprecated map[string]string, migrate bool) ResolveOption {
+	return func(o *resolveOptions) {
+		o.deprecatedKeys = deprecated
+		o.migrateDeprecatedKeys = migrate
+	}
+}
+
+// WithRequiredKeys makes ResolveConfigurations fail when any of the given
+// keys is missing from the merged configuration. A missing key present in
+// fallbacks is filled with the fallback value instead of failing, and a
+// synthetic ConfigSource of kind "fallback" is recorded in the sources list.
+// Unlike WithDefaults, fallbacks only apply to required keys and do not
+// participate in the merge at all when a source supplies the key.
+func WithRequiredKeys(keys []string, fallbacks map[string]string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.requiredKeys = keys
+		o.requiredFallbacks = fallbacks
+	}
+}
+
+// WithImmutableKeys pins the listed keys to whatever the first source that
+// sets them provides: no later source may change them, regardless of its
+// priority, and any attempt is logged. This differs from allow/deny key
+// filtering, which only privileges the first source in the list; immutability
+// follows whichever source happens to set the key first.
+func WithImmutableKeys(keys []string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.immutableKeys = sets.New(keys...)
+	}
+}
+
+// WithCNCPriority selects the priority mode used when multiple matching
+// CiliumNodeConfigs set the same key.
+func WithCNCPriority(mode CNCPriorityMode) ResolveOption {
+	return func(o *resolveOptions) {
+		o.cncPriority = mode
+	}
+}
+
+// WithValueTransforms applies light transformations to the final merged value
+// of matching keys, e.g. trimming a trailing newline added by kubectl edit. A
+// wildcard "*" entry applies to every key, before any per-key transform.
+// Transforms run after the merge and do not affect the synthetic provenance
+// keys.
+func WithValueTransforms(transforms map[string]func(string) string) ResolveOption {
+	return func(o *resolveOptions) {
+		o.transforms = transforms
+	}
+}
+
+// WithOverrideLogLevel sets the level at which routine per-key override
+// messages ("Source overrides key") are logged, defaulting to info. Large
+// CiliumNodeConfigs can produce one such line per key, so deployments may
+// demote them to debug. Genuine problems - invalid keys, denied overrides,
+// immutable-key violations - keep their warn/error level regardless.
+func WithOverrideLogLevel(level slog.Level) ResolveOption {
+	return func(o *resolveOptions) {
+		o.overrideLogLevel = level
+	}
+}
+
+// WithIgnoreEmptyValues treats an empty string from a higher-priority source
+// as "unset": it does not override a non-empty value a lower-priority source
+// already provided. With no arguments the policy applies to every key,
+// otherwise only to the listed ones. The default keeps the usual clobber
+// behavior, where the higher-priority source always wins.
+func WithIgnoreEmptyValues(keys ...string) ResolveOption {
+	return func(o *resolveOptions) {
+		if len(keys) == 0 {
+			o.ignoreEmptyAll = true
+			return
+		}
+		o.ignoreEmptyKeys = sets.New(keys...)
+	}
+}
+
+// WithHTTPClient sets the http.Client used to fetch KindHTTP sources, e.g. to
+// configure TLS or authentication. Without it, http.DefaultClient is used.
+// Each fetch is bounded by httpSourceTimeout either way.
+func WithHTTPClient(c *http.Client) ResolveOption {
+	return func(o *resolveOptions) {
+		o.httpClient = c
+	}
+}
+
+// WithMetrics reports override activity to the given metrics: one counter
+// increment per key a higher-priority source overrides, and one per key
+// dropped by the allow/deny lists, both labeled with the source kind. Without
+// this option no metrics are emitted.
+func WithMetrics(m *Metrics) ResolveOption {
+	return func(o *resolveOptions) {
+		o.metrics = m
+	}
+}
+
+// WithConcurrentReads makes ResolveConfigurations fetch all sources in
+// parallel, with at most limit reads in flight, before merging the pre-fetched
+// results sequentially in the original source order. Reads are side-effect
+// free, so the merged output is identical to the sequential default. Limits
+// below 2 keep reads sequential.
+func WithConcurrentReads(limit int) ResolveOption {
+	return func(o *resolveOptions) {
+		o.readConcurrency = limit
+	}
+}
+
+// WriteOption customizes the behavior of WriteConfigurations.
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	sealedKeys sets.Set[string]
+	seal       func(value string) (string, error)
+}
+
+// WithSealedKeys encrypts the values of the listed keys with the given sealing
+// function before they are written to disk; all other keys are written as
+// plaintext. Use ReadConfigurations with WithUnsealedKeys to read them back.
+func WithSealedKeys(keys []string, seal func(value string) (string, error)) WriteOption {
+	return func(o *writeOptions) {
+		o.sealedKeys = sets.New(keys...)
+		o.seal = seal
+	}
+}
+
+// ReadOption customizes the behavior of ReadConfigurations.
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	sealedKeys sets.Set[string]
+	unseal     func(value string) (string, error)
+}
+
+// WithUnsealedKeys decrypts the values of the listed keys with the given
+// unsealing function as they are read back, reversing WithSealedKeys.
+func WithUnsealedKeys(keys []string, unseal func(value string) (string, error)) ReadOption {
+	return func(o *readOptions) {
+		o.sealedKeys = sets.New(keys...)
+		o.unseal = unseal
+	}
+}
@@ -8,17 +8,24 @@ package resolver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	apivalidation "k8s.io/apimachinery/pkg/util/validation"
 
@@ -33,16 +40,80 @@ import (
 const (
 	KindConfigMap  = "config-map"
 	KindNode       = "node"
+	KindPod        = "pod"
 	KindNodeConfig = "cilium-node-config"
+	KindHTTP       = "http"
+	KindDefault    = "default"
+	KindFallback   = "fallback"
+
+	// KindCRDStatus reads override key-value pairs from the status of an
+	// arbitrary CRD object, via the dynamic client provided through
+	// WithDynamicClient. Used when an operator computes per-node config and
+	// publishes it on a CRD's status subresource.
+	KindCRDStatus = "crd-status"
+
+	// KindSealedConfigMap reads a sealed blob from the "sealed-config" key
+	// of a ConfigMap and decrypts it with the unseal function provided via
+	// WithUnsealFunc. Crypto stays with the caller.
+	KindSealedConfigMap = "sealed-config-map"
+
+	// SchemaVersionKey is the key a source may set to declare which config
+	// schema version its keys follow, gating schema migrations.
+	SchemaVersionKey = "config-schema-version"
+
+	// sealedBlobKey is the ConfigMap key a KindSealedConfigMap source reads
+	// the sealed blob from.
+	sealedBlobKey = "sealed-config"
+
+	// podNameEnv and podNamespaceEnv are the downward-API environment
+	// variables consulted when a KindPod source does not carry an explicit
+	// name or namespace.
+	podNameEnv      = "K8S_POD_NAME"
+	podNamespaceEnv = "K8S_POD_NAMESPACE"
 
 	ConfigSources          = "config-sources"
 	ConfigSourcesOverrides = "config-sources-overrides"
 )
 
 type ConfigSource struct {
-	Kind      string `json:"kind"`      // one of KindConfigMap, KindNodeConfig, KindNode
+	Kind      string `json:"kind"`      // one of KindConfigMap, KindNodeConfig, KindNode, KindPod, KindHTTP
 	Namespace string `json:"namespace"` // The namespace for the ConfigMap, CiliumNodeConfigs or empty for Node
-	Name      string `json:"name"`      // The name of the ConfigMap or Node, unused for Overrides
+	Name      string `json:"name"`      // The name of the ConfigMap or Node, or the URL for KindHTTP, unused for Overrides
+
+	// Namespaces optionally fans a KindConfigMap source out over multiple
+	// namespaces: the ConfigMap with name Name is read from every listed
+	// namespace, in order, with later namespaces overriding earlier ones.
+	// Missing ConfigMaps are skipped. When set, Namespace is ignored.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selector optionally restricts which CiliumNodeConfig objects a
+	// KindNodeConfig source considers, by a label selector on the CNC objects
+	// themselves. It applies before the per-CNC NodeSelector is evaluated and
+	// is ignored when Name is set.
+	Selector string `json:"selector,omitempty"`
+
+	// Keys optionally restricts this source to the listed keys: anything else
+	// it provides is discarded before the merge. Useful when a shared
+	// ConfigMap carries keys unrelated to Cilium. Unlike the global
+	// allow/deny override lists, this scopes a single source.
+	Keys []string `json:"keys,omitempty"`
+
+	// Resource identifies the resource a KindCRDStatus source reads, in the
+	// "resource.version.group" form accepted by kubectl get (e.g.
+	// "ciliumconfigs.v1alpha1.example.io").
+	Resource string `json:"resource,omitempty"`
+
+	// StatusPath is the dot-separated path below .status at which a
+	// KindCRDStatus source finds its string map of overrides (e.g.
+	// "computed.overrides"). Empty reads .status itself.
+	StatusPath string `json:"status-path,omitempty"`
+
+	// StripKeyPrefixes optionally strips the first matching prefix from every
+	// key this source provides, before the merge. Useful when keys are
+	// namespaced in a shared ConfigMap (e.g. "acme.config/bpf-lb-mode") but
+	// Cilium expects the bare key. Reading the source fails if two keys strip
+	// to the same bare key.
+	StripKeyPrefixes []string `json:"strip-key-prefixes,omitempty"`
 }
 type ConfigOverride struct {
 	AllowConfigKeys []string `json:"allowConfigKeys"` // List of configuration keys that are allowed to be overridden (e.g. set from not the first source. Takes precedence over deny-config-keys
@@ -53,10 +124,46 @@ func (cs *ConfigSource) String() string {
 	return fmt.Sprintf("%s:%s/%s", cs.Kind, cs.Namespace, cs.Name)
 }
 
-func ResolveConfigurations(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string, sources []ConfigSource, allowConfigKeys, denyConfigKeys []string) (map[string]string, error) {
+// ErrAllowDenyConflict is returned by ConfigOverride.Validate when both
+// AllowConfigKeys and DenyConfigKeys are populated. The deny list is ignored
+// in that case.
+var ErrAllowDenyConflict = errors.New("both allow-config-keys and deny-config-keys are set; deny-config-keys is ignored")
+
+// ErrInvalidSourceKind is returned when a ConfigSource has a kind other than
+// the Kind* constants. Callers can use errors.Is to tell this configuration
+// mistake apart from transient read failures, which may be worth retrying.
+var ErrInvalidSourceKind = errors.New("invalid config source kind")
+
+// Validate returns ErrAllowDenyConflict when both the allow and the deny list
+// are populated, making the (surprising) precedence rule explicit: the deny
+// list does nothing as soon as an allow list is given.
+func (o ConfigOverride) Validate() error {
+	if len(o.AllowConfigKeys) > 0 && len(o.DenyConfigKeys) > 0 {
+		return ErrAllowDenyConflict
+	}
+	return nil
+}
+
+func ResolveConfigurations(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string, sources []ConfigSource, allowConfigKeys, denyConfigKeys []string, opts ...ResolveOption) (map[string]string, error) {
+	var options resolveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	config := map[string]string{}
+	if len(options.defaults) > 0 {
+		config = maps.Clone(options.defaults)
+	}
+	sourceSetKeys := sets.New[string]()   // keys set by an actual source, to detect which defaults survived
 	var sourceDescriptions []ConfigSource // We want to keep track of which unique sources we actually use in order of source priority
 
+	if err := (ConfigOverride{AllowConfigKeys: allowConfigKeys, DenyConfigKeys: denyConfigKeys}).Validate(); err != nil {
+		logger.Warn(
+			"Conflicting config override specification",
+			logfields.Error, err,
+		)
+	}
+
 	// matchKeys is a set of keys that are either allowed or denied
 	var matchKeys sets.Set[string]
 	allowIfMatch := false // if true, then this is an allow list; otherwise a deny list
@@ -67,9 +174,43 @@ func ResolveConfigurations(ctx context.Context, logger *slog.Logger, client clie
 		matchKeys = sets.New(denyConfigKeys...)
 	}
 
+	type sourceResult struct {
+		config       map[string]string
+		descriptions []ConfigSource
+		err          error
+	}
+
+	// Read phase: fetch all sources, optionally with bounded concurrency.
+	// Reads are side-effect free, so the merge below stays deterministic as
+	// long as it consumes the results in the original source order.
+	results := make([]sourceResult, len(sources))
+	if options.readConcurrency > 1 {
+		sem := make(chan struct{}, options.readConcurrency)
+		var wg sync.WaitGroup
+		for i, source := range sources {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				c, descs, err := readConfigSource(ctx, logger, client, nodeName, source, options)
+				results[i] = sourceResult{config: c, descriptions: descs, err: err}
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, source := range sources {
+			c, descs, err := readConfigSource(ctx, logger, client, nodeName, source, options)
+			results[i] = sourceResult{config: c, descriptions: descs, err: err}
+			if err != nil {
+				break
+			}
+		}
+	}
+
 	first := true
-	for _, source := range sources {
-		c, descs, err := ReadConfigSource(ctx, logger, client, nodeName, source)
+	for i, source := range sources {
+		c, descs, err := results[i].config, results[i].descriptions, results[i].err
 		if err != nil {
 			return nil, fmt.Errorf("failed to read config source %s: %w", source.String(), err)
 		}
@@ -78,6 +219,30 @@ func ResolveConfigurations(ctx context.Context, logger *slog.Logger, client clie
 			logfields.LenConfigPairs, len(c),
 			logfields.ConfigSource, source,
 		)
+		if options.normalizeKeys {
+			c, err = normalizeConfigKeys(logger, source, c, options.lowercaseKeys)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(options.schemaMigrations) > 0 {
+			c = applySchemaMigrations(logger, source, c, options.schemaMigrations)
+		}
+		if len(options.deprecatedKeys) > 0 {
+			c = applyDeprecatedKeys(logger, source, c, options.deprecatedKeys, options.migrateDeprecatedKeys)
+		}
+		// Immutable keys stay with whichever source set them first, no
+		// matter where that source or this one sit in the priority order.
+		for k := range c {
+			if options.immutableKeys.Has(k) && sourceSetKeys.Has(k) {
+				logger.Warn(
+					"Source attempts to change immutable key",
+					logfields.ConfigKey, k,
+					logfields.ConfigSource, source,
+				)
+				delete(c, k)
+			}
+		}
 		if !first {
 			for k := range c {
 				if matchKeys != nil && !(matchKeys.Has(k) == allowIfMatch) {
@@ -86,14 +251,77 @@ func ResolveConfigurations(ctx context.Context, logger *slog.Logger, client clie
 						logfields.ConfigKey, k,
 						logfields.ConfigSource, source,
 					)
+					if options.metrics != nil {
+						options.metrics.ConfigOverridesDenied.WithLabelValues(source.Kind).Inc()
+					}
 					delete(c, k)
 				}
 			}
 		}
 		first = false
 		if len(c) != 0 {
-			config = mergeConfig(logger, source, config, c)
+			config = mergeConfig(logger, options, source, config, c)
 			sourceDescriptions = append(sourceDescriptions, descs...)
+			for k := range c {
+				sourceSetKeys.Insert(k)
+			}
+		}
+	}
+
+	// Record the synthetic default source when at least one default survived
+	// as the winning value for its key. Defaults are the lowest priority, so
+	// they are prepended.
+	for k := range options.defaults {
+		if !sourceSetKeys.Has(k) {
+			sourceDescriptions = append([]ConfigSource{{Kind: KindDefault}}, sourceDescriptions...)
+			break
+		}
+	}
+
+	// Required keys must be supplied by some source. Keys with a fallback are
+	// filled in and attributed to a synthetic fallback source; the rest are
+	// hard errors.
+	usedFallback := false
+	for _, k := range options.requiredKeys {
+		if _, ok := config[k]; ok {
+			continue
+		}
+		if v, ok := options.requiredFallbacks[k]; ok {
+			config[k] = v
+			usedFallback = true
+			continue
+		}
+		return nil, fmt.Errorf("required key %s not supplied by any config source", k)
+	}
+	if usedFallback {
+		sourceDescriptions = append([]ConfigSource{{Kind: KindFallback}}, sourceDescriptions...)
+	}
+
+	// A single summary line for dashboards, complementing the per-source logs
+	// above: how many of the provided sources actually contributed, and how
+	// many keys the resolve produced. A source listed multiple times (or
+	// described by overlapping node config entries) counts once.
+	usedSources := sets.New[string]()
+	for i := range sourceDescriptions {
+		usedSources.Insert(sourceDescriptions[i].String())
+	}
+	logger.Info(
+		"Resolved configuration",
+		logfields.ConfigSourcesProvided, len(sources),
+		logfields.ConfigSourcesUsed, usedSources.Len(),
+		logfields.ConfigKeysResolved, len(config),
+	)
+
+	if len(options.transforms) > 0 {
+		wildcard := options.transforms["*"]
+		for k, v := range config {
+			if wildcard != nil {
+				v = wildcard(v)
+			}
+			if transform, ok := options.transforms[k]; ok && k != "*" {
+				v = transform(v)
+			}
+			config[k] = v
 		}
 	}
 
@@ -113,16 +341,133 @@ func ResolveConfigurations(ctx context.Context, logger *slog.Logger, client clie
 	return config, nil
 }
 
-func mergeConfig(logger *slog.Logger, source ConfigSource, lower, upper map[string]string) map[string]string {
+// applySchemaMigrations renames deprecated keys in c based on the schema
+// version the source declares via SchemaVersionKey. Sources declaring no
+// version, or a version without a migration table, pass through untouched. A
+// deprecated key is dropped rather than renamed when the source also sets
+// the key under its new name, so a migration never clobbers an explicit
+// value.
+func applySchemaMigrations(logger *slog.Logger, source ConfigSource, c map[string]string, migrations map[string]map[string]string) map[string]string {
+	renames, ok := migrations[c[SchemaVersionKey]]
+	if !ok {
+		return c
+	}
+	for oldKey, newKey := range renames {
+		v, ok := c[oldKey]
+		if !ok {
+			continue
+		}
+		if _, exists := c[newKey]; exists {
+			logger.Warn(
+				"Dropping deprecated config key also set under its new name",
+				logfields.ConfigKey, oldKey,
+				logfields.ConfigSource, source,
+			)
+			delete(c, oldKey)
+			continue
+		}
+		logger.Info(
+			"Renaming deprecated config key",
+			logfields.ConfigKey, oldKey,
+			logfields.Name, newKey,
+			logfields.ConfigSource, source,
+		)
+		c[newKey] = v
+		delete(c, oldKey)
+	}
+	return c
+}
+
+// applyDeprecatedKeys warns whenever a source still sets a key registered via
+// WithDeprecatedKeys, pointing at the registered replacement. When migrate is
+// set, a replacement naming a key (no spaces) receives the deprecated key's
+// value; a deprecated key set alongside its replacement is dropped so the
+// replacement wins.
+func applyDeprecatedKeys(logger *slog.Logger, source ConfigSource, c map[string]string, deprecated map[string]string, migrate bool) map[string]string {
+	for oldKey, replacement := range deprecated {
+		v, ok := c[oldKey]
+		if !ok {
+			continue
+		}
+		logger.Warn(
+			"Source sets deprecated config key",
+			logfields.ConfigKey, oldKey,
+			logfields.Hint, replacement,
+			logfields.ConfigSource, source,
+		)
+		if !migrate || strings.Contains(replacement, " ") {
+			continue
+		}
+		if _, exists := c[replacement]; exists {
+			logger.Warn(
+				"Dropping deprecated config key also set under its replacement",
+				logfields.ConfigKey, oldKey,
+				logfields.ConfigSource, source,
+			)
+			delete(c, oldKey)
+			continue
+		}
+		c[replacement] = v
+		delete(c, oldKey)
+	}
+	return c
+}
+
+// normalizeConfigKeys trims surrounding whitespace from the keys in c and
+// optionally lowercases them. Keys that are invalid after normalization are
+// logged and dropped. An error is returned when two distinct keys normalize
+// to the same key, since the merged value would depend on map iteration order.
+func normalizeConfigKeys(logger *slog.Logger, source ConfigSource, c map[string]string, lowercase bool) (map[string]string, error) {
+	out := make(map[string]string, len(c))
+	originals := make(map[string]string, len(c)) // normalized key -> original key
+
+	for k, v := range c {
+		key := strings.TrimSpace(k)
+		if lowercase {
+			key = strings.ToLower(key)
+		}
+		if errs := apivalidation.IsConfigMapKey(key); len(errs) > 0 {
+			logger.Error(
+				"Dropping invalid key after normalization",
+				logfields.Errors, errs,
+				logfields.ConfigKey, k,
+				logfields.ConfigSource, source,
+			)
+			continue
+		}
+		if prev, conflict := originals[key]; conflict {
+			return nil, fmt.Errorf("keys %q and %q in source %s both normalize to %q", prev, k, source.String(), key)
+		}
+		originals[key] = k
+		out[key] = v
+	}
+
+	return out, nil
+}
+
+func mergeConfig(logger *slog.Logger, options resolveOptions, source ConfigSource, lower, upper map[string]string) map[string]string {
 	out := maps.Clone(lower)
 
 	for k, v := range upper {
-		if _, set := out[k]; set {
-			logger.Info(
+		if prev, set := out[k]; set {
+			if v == "" && prev != "" && options.ignoresEmpty(k) {
+				logger.Log(
+					context.Background(), options.overrideLogLevel,
+					"Ignoring empty override for key",
+					logfields.ConfigKey, k,
+					logfields.ConfigSource, source,
+				)
+				continue
+			}
+			logger.Log(
+				context.Background(), options.overrideLogLevel,
 				"Source overrides key",
 				logfields.ConfigKey, k,
 				logfields.ConfigSource, source,
 			)
+			if options.metrics != nil {
+				options.metrics.ConfigOverrides.WithLabelValues(source.Kind).Inc()
+			}
 		}
 		out[k] = v
 	}
@@ -135,7 +480,12 @@ func mergeConfig(logger *slog.Logger, source ConfigSource, lower, upper map[stri
 // atomic updates:
 // destDir/key -> ..data/key
 // ..data -> ..data_$time
-func WriteConfigurations(ctx context.Context, logger *slog.Logger, destDir string, data map[string]string) error {
+func WriteConfigurations(ctx context.Context, logger *slog.Logger, destDir string, data map[string]string, opts ...WriteOption) error {
+	var options writeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	dataDirName := fmt.Sprintf("..data_%d", time.Now().Unix())
 	err := os.MkdirAll(filepath.Join(destDir, dataDirName), 0777)
 	if err != nil {
@@ -151,6 +501,16 @@ func WriteConfigurations(ctx context.Context, logger *slog.Logger, destDir strin
 			continue
 		}
 
+		// Sensitive keys are sealed before they touch the disk; everything
+		// else is written as plaintext.
+		if options.sealedKeys.Has(k) {
+			sealed, err := options.seal(v)
+			if err != nil {
+				return fmt.Errorf("failed to seal config key %s: %w", k, err)
+			}
+			v = sealed
+		}
+
 		dest := filepath.Join(destDir, dataDirName, k)
 		if err := os.WriteFile(dest, []byte(v), 0644); err != nil {
 			return fmt.Errorf("failed to write config key at %s: %w", dest, err)
@@ -176,39 +536,394 @@ func WriteConfigurations(ctx context.Context, logger *slog.Logger, destDir strin
 	return nil
 }
 
+// WriteConfigurationsMulti writes the key-value pairs in data to every
+// directory in destDirs. If any write fails, the directories updated so far
+// are flipped back to their previous ..data target so consumers do not see a
+// mix of old and new configuration. True cross-directory atomicity is not
+// possible with the symlink layout; this is best-effort consistency: a
+// rollback can itself fail, in which case the failure is logged and the
+// original write error is still returned.
+func WriteConfigurationsMulti(ctx context.Context, logger *slog.Logger, destDirs []string, data map[string]string) error {
+	type priorState struct {
+		dir    string
+		target string // previous ..data target, empty when the directory was fresh
+	}
+	var written []priorState
+
+	for _, dir := range destDirs {
+		prev, err := os.Readlink(filepath.Join(dir, "..data"))
+		if err != nil {
+			prev = ""
+		}
+		if err := WriteConfigurations(ctx, logger, dir, data); err != nil {
+			for _, p := range written {
+				if p.target == "" {
+					continue
+				}
+				if rbErr := replaceDataSymlink(p.dir, p.target); rbErr != nil {
+					logger.Error(
+						"Failed to roll back config directory",
+						logfields.Path, p.dir,
+						logfields.Error, rbErr,
+					)
+				}
+			}
+			return fmt.Errorf("failed to write configuration to %s: %w", dir, err)
+		}
+		written = append(written, priorState{dir: dir, target: prev})
+	}
+
+	return nil
+}
+
+// replaceDataSymlink points destDir/..data at target using the same
+// tmp-then-rename dance as WriteConfigurations.
+func replaceDataSymlink(destDir, target string) error {
+	_ = os.Remove(filepath.Join(destDir, "..data.tmp"))
+	if err := os.Symlink(target, filepath.Join(destDir, "..data.tmp")); err != nil {
+		return fmt.Errorf("failed to write ..data.tmp symlink: %w", err)
+	}
+	if err := os.Rename(filepath.Join(destDir, "..data.tmp"), filepath.Join(destDir, "..data")); err != nil {
+		return fmt.Errorf("failed to move ..data symlink in to place: %w", err)
+	}
+	return nil
+}
+
+// KeyType enumerates the value types ValidateAgainstSchema understands.
+type KeyType string
+
+const (
+	KeyTypeString KeyType = "string"
+	KeyTypeInt    KeyType = "int"
+	KeyTypeBool   KeyType = "bool"
+	KeyTypeEnum   KeyType = "enum"
+)
+
+// KeySpec describes the allowed shape of a single config key's value.
+type KeySpec struct {
+	// Type is one of KeyTypeString, KeyTypeInt, KeyTypeBool or KeyTypeEnum.
+	Type KeyType
+	// Values lists the allowed values for KeyTypeEnum keys.
+	Values []string
+}
+
+// ValidateAgainstSchema checks every key in config against schema, returning
+// one error per violation: a value that does not parse as the declared type,
+// an enum value outside the allowed set, or a key absent from the schema
+// entirely. The synthetic provenance keys injected by ResolveConfigurations
+// are skipped. Errors are ordered by key; a nil return means the config
+// conforms. Whether violations fail the resolve or are merely surfaced as
+// warnings is up to the caller.
+func ValidateAgainstSchema(config map[string]string, schema map[string]KeySpec) []error {
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(config)) {
+		if k == ConfigSources || k == ConfigSourcesOverrides {
+			continue
+		}
+		v := config[k]
+		spec, ok := schema[k]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key %s", k))
+			continue
+		}
+		switch spec.Type {
+		case KeyTypeString:
+		case KeyTypeInt:
+			if _, err := strconv.Atoi(v); err != nil {
+				errs = append(errs, fmt.Errorf("key %s: value %q is not an integer", k, v))
+			}
+		case KeyTypeBool:
+			if _, err := strconv.ParseBool(v); err != nil {
+				errs = append(errs, fmt.Errorf("key %s: value %q is not a boolean", k, v))
+			}
+		case KeyTypeEnum:
+			if !slices.Contains(spec.Values, v) {
+				errs = append(errs, fmt.Errorf("key %s: value %q is not one of %v", k, v, spec.Values))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("key %s: schema declares unknown type %q", k, spec.Type))
+		}
+	}
+	return errs
+}
+
+// StripMetadataKeys returns a copy of data without the synthetic
+// ConfigSources and ConfigSourcesOverrides keys that ResolveConfigurations
+// injects. Useful when a previously written configuration is read back for
+// re-resolution or comparison, where the provenance metadata would pollute
+// the result. The input map is not mutated.
+func StripMetadataKeys(data map[string]string) map[string]string {
+	out := maps.Clone(data)
+	delete(out, ConfigSources)
+	delete(out, ConfigSourcesOverrides)
+	return out
+}
+
+// ChangedKeys compares data against the configuration currently materialized
+// in destDir by WriteConfigurations and returns the sorted keys whose values
+// differ, including keys present on only one side. An empty result means the
+// resolved configuration matches what is on disk and a reload can be skipped
+// entirely. A destDir with no materialized configuration reports every key
+// as changed.
+func ChangedKeys(destDir string, data map[string]string) ([]string, error) {
+	dataDir := filepath.Join(destDir, "..data")
+	current := map[string]string{}
+	entries, err := os.ReadDir(dataDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read current configuration in %s: %w", destDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(dataDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read current value of key %s: %w", entry.Name(), err)
+		}
+		current[entry.Name()] = string(contents)
+	}
+
+	changed := sets.New[string]()
+	for k, v := range data {
+		if cur, ok := current[k]; !ok || cur != v {
+			changed.Insert(k)
+		}
+	}
+	for k := range current {
+		if _, ok := data[k]; !ok {
+			changed.Insert(k)
+		}
+	}
+	if changed.Len() == 0 {
+		return nil, nil
+	}
+	return sets.List(changed), nil
+}
+
+// envFileKey converts a config key to environment-variable style: uppercased,
+// with hyphens replaced by underscores. E.g. "enable-ipv6" becomes
+// "ENABLE_IPV6".
+func envFileKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// WriteConfigurationsEnvFile writes the key-value pairs in data to destPath
+// as an env file: one KEY=value line per pair, sorted by key, with keys
+// transformed by envFileKey. Values containing whitespace, quotes or other
+// characters that would break the line-oriented format are quoted with Go
+// string syntax. The file is written atomically via a temp file and rename,
+// for consumers that read config from env files rather than the symlinked
+// directory layout.
+func WriteConfigurationsEnvFile(destPath string, data map[string]string) error {
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		v := data[k]
+		if strings.ContainsAny(v, " \t\n\r\"'\\$#") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", envFileKey(k), v)
+	}
+
+	tmp := destPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write env file %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, destPath); err != nil {
+		return fmt.Errorf("failed to move env file in to place: %w", err)
+	}
+	return nil
+}
+
+// ReadConfigurations reads back a directory in the layout produced by
+// WriteConfigurations: it resolves the ..data symlink and reads each keyed
+// file into the returned map. This is the inverse of WriteConfigurations and
+// also works for Kubernetes projected volumes, which use the same layout.
+func ReadConfigurations(dir string, opts ...ReadOption) (map[string]string, error) {
+	var options readOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	dataDirName, err := os.Readlink(filepath.Join(dir, "..data"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ..data symlink in %s: %w", dir, err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, dataDirName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data directory %s: %w", filepath.Join(dir, dataDirName), err)
+	}
+
+	out := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		v, err := os.ReadFile(filepath.Join(dir, dataDirName, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config key at %s: %w", filepath.Join(dir, dataDirName, entry.Name()), err)
+		}
+		value := string(v)
+		if options.sealedKeys.Has(entry.Name()) {
+			value, err = options.unseal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unseal config key %s: %w", entry.Name(), err)
+			}
+		}
+		out[entry.Name()] = value
+	}
+
+	return out, nil
+}
+
 func ReadConfigSource(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string, source ConfigSource) (config map[string]string, sources []ConfigSource, err error) {
+	return readConfigSource(ctx, logger, client, nodeName, source, resolveOptions{})
+}
+
+func readConfigSource(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string, source ConfigSource, options resolveOptions) (config map[string]string, sources []ConfigSource, err error) {
 	logger.Info(
 		"Reading configuration from source",
 		logfields.ConfigSource, source,
 	)
 	switch source.Kind {
 	case KindNode:
-		return readNodeOverrides(ctx, logger, client, source.Name)
+		config, sources, err = readNodeOverrides(ctx, logger, client, source.Name, options.nodeOverridePrefixes)
+	case KindPod:
+		config, sources, err = readPodOverrides(ctx, logger, client, source)
 	case KindConfigMap:
-		return readConfigMap(ctx, logger, client, source)
+		config, sources, err = readConfigMap(ctx, logger, client, source, options)
 	case KindNodeConfig:
-		return readNodeConfigsAllVersions(ctx, logger, client, nodeName, source.Namespace, source.Name)
+		config, sources, err = readNodeConfigsAllVersions(ctx, logger, client, nodeName, source.Namespace, source.Name, source.Selector, options.cncPriority)
+	case KindCRDStatus:
+		config, sources, err = readCRDStatus(ctx, logger, source, options)
+	case KindHTTP:
+		config, sources, err = readHTTPSource(ctx, logger, source, options)
+	case KindSealedConfigMap:
+		config, sources, err = readSealedConfigMap(ctx, logger, client, source, options)
+	default:
+		return nil, nil, fmt.Errorf("%w %q", ErrInvalidSourceKind, source.Kind)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
-	return nil, nil, fmt.Errorf("invalid source kind %s", source.Kind)
+
+	// A per-source allowlist scopes what this one source may contribute,
+	// before the merge and the global override lists see it.
+	if len(source.Keys) > 0 {
+		keep := sets.New(source.Keys...)
+		maps.DeleteFunc(config, func(k, _ string) bool { return !keep.Has(k) })
+		if len(config) == 0 {
+			return nil, nil, nil
+		}
+	}
+	// Strip configured key prefixes, failing on collisions: two prefixed
+	// keys silently collapsing to one bare key would make the winner depend
+	// on map iteration order.
+	if len(source.StripKeyPrefixes) > 0 {
+		stripped := make(map[string]string, len(config))
+		origin := make(map[string]string, len(config)) // bare key -> original key
+		for k, v := range config {
+			bare := k
+			for _, prefix := range source.StripKeyPrefixes {
+				if rest, ok := strings.CutPrefix(k, prefix); ok {
+					bare = rest
+					break
+				}
+			}
+			if prev, collision := origin[bare]; collision {
+				return nil, nil, fmt.Errorf("keys %q and %q in %s both strip to %q", prev, k, source.String(), bare)
+			}
+			origin[bare] = k
+			stripped[bare] = v
+		}
+		config = stripped
+	}
+	return config, sources, nil
 }
 
-func readNodeOverrides(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string) (map[string]string, []ConfigSource, error) {
+// readCRDStatus reads override key-value pairs from the status of the CRD
+// object named by the source, through the dynamic client provided via
+// WithDynamicClient. The source's StatusPath selects where below .status the
+// string map of overrides lives. A missing object yields no config, like a
+// missing ConfigMap; a present object whose status does not hold a string map
+// at the path is an error.
+func readCRDStatus(ctx context.Context, logger *slog.Logger, source ConfigSource, options resolveOptions) (map[string]string, []ConfigSource, error) {
+	if options.dynamicClient == nil {
+		return nil, nil, fmt.Errorf("reading source %s requires a dynamic client, see WithDynamicClient", source.String())
+	}
+	gvr, _ := schema.ParseResourceArg(source.Resource)
+	if gvr == nil {
+		return nil, nil, fmt.Errorf("invalid resource %q in source %s: expected the resource.version.group form", source.Resource, source.String())
+	}
+
+	obj, err := options.dynamicClient.Resource(*gvr).Namespace(source.Namespace).Get(ctx, source.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info(
+				"Configuration source object not found, ignoring",
+				logfields.ConfigSource, source,
+			)
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get %s %s/%s: %w", source.Resource, source.Namespace, source.Name, err)
+	}
+
+	fields := []string{"status"}
+	if source.StatusPath != "" {
+		fields = append(fields, strings.Split(source.StatusPath, ".")...)
+	}
+	out, found, err := unstructured.NestedStringMap(obj.Object, fields...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("status path %q of %s %s/%s does not hold a string map: %w", source.StatusPath, source.Resource, source.Namespace, source.Name, err)
+	}
+	if !found {
+		logger.Warn(
+			"Configuration source object has no config at the status path",
+			logfields.ConfigSource, source,
+			logfields.Resource, source.StatusPath,
+		)
+		return nil, nil, nil
+	}
+
+	for key := range out {
+		if errs := apivalidation.IsConfigMapKey(key); len(errs) > 0 {
+			logger.Warn(
+				"Object status contains invalid config key, ignoring",
+				logfields.Errors, errs,
+				logfields.ConfigSource, source,
+				logfields.ConfigKey, key,
+			)
+			delete(out, key)
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil, nil
+	}
+
+	return out, []ConfigSource{{Kind: KindCRDStatus, Namespace: source.Namespace, Name: source.Name}}, nil
+}
+
+func readNodeOverrides(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string, prefixes []string) (map[string]string, []ConfigSource, error) {
 	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not get Node %s: %w", nodeName, err)
 	}
 
 	// We allow overriding individual key-value pairs by annotating the Node object
-	// with config.cilium.io/K=V
+	// with config.cilium.io/K=V. Additional prefixes may be layered on top via
+	// WithNodeOverridePrefixes, later prefixes winning.
+	if len(prefixes) == 0 {
+		prefixes = []string{annotation.ConfigPrefix}
+	}
 	out := map[string]string{}
 
-	read := func(in map[string]string) {
+	read := func(in map[string]string, prefix string, layer map[string]string) {
 		for k, v := range in {
-			if strings.HasPrefix(k, annotation.ConfigPrefix) {
+			if strings.HasPrefix(k, prefix) {
 				s := strings.SplitN(k, "/", 2)
 				if len(s) != 2 {
 					logger.Error(
-						fmt.Sprintf("Node annotation format invalid: should be of the format %s/<KEY>", annotation.ConfigPrefix),
+						fmt.Sprintf("Node annotation format invalid: should be of the format %s/<KEY>", prefix),
 						logfields.ConfigAnnotation, k,
 					)
 					continue
@@ -222,13 +937,26 @@ func readNodeOverrides(ctx context.Context, logger *slog.Logger, client client.C
 					)
 					continue
 				}
-				out[key] = v
+				layer[key] = v
 			}
 		}
 	}
 
-	read(node.Labels)
-	read(node.Annotations)
+	for _, prefix := range prefixes {
+		layer := map[string]string{}
+		read(node.Labels, prefix, layer)
+		read(node.Annotations, prefix, layer)
+		for k, v := range layer {
+			if prev, conflict := out[k]; conflict && prev != v {
+				logger.Warn(
+					"Node override key set under multiple annotation prefixes, later prefix wins",
+					logfields.ConfigKey, k,
+					logfields.ConfigAnnotation, prefix+"/"+k,
+				)
+			}
+			out[k] = v
+		}
+	}
 	if len(out) == 0 {
 		return nil, nil, nil
 	}
@@ -236,52 +964,275 @@ func readNodeOverrides(ctx context.Context, logger *slog.Logger, client client.C
 	return out, []ConfigSource{{Kind: KindNode, Namespace: "", Name: nodeName}}, nil
 }
 
-func readConfigMap(ctx context.Context, logger *slog.Logger, client client.Clientset, source ConfigSource) (map[string]string, []ConfigSource, error) {
+// readPodOverrides reads per-instance overrides from the agent Pod's own
+// annotations, using the same config.cilium.io/K=V convention and validation
+// as readNodeOverrides. The Pod name and namespace come from the source, with
+// the downward-API environment as a fallback.
+func readPodOverrides(ctx context.Context, logger *slog.Logger, client client.Clientset, source ConfigSource) (map[string]string, []ConfigSource, error) {
+	podName := source.Name
+	if podName == "" {
+		podName = os.Getenv(podNameEnv)
+	}
+	podNamespace := source.Namespace
+	if podNamespace == "" {
+		podNamespace = os.Getenv(podNamespaceEnv)
+	}
+	if podName == "" {
+		return nil, nil, fmt.Errorf("pod source has no name and %s is not set", podNameEnv)
+	}
+
+	pod, err := client.CoreV1().Pods(podNamespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get Pod %s/%s: %w", podNamespace, podName, err)
+	}
+
+	out := map[string]string{}
+	for k, v := range pod.Annotations {
+		if !strings.HasPrefix(k, annotation.ConfigPrefix) {
+			continue
+		}
+		s := strings.SplitN(k, "/", 2)
+		if len(s) != 2 {
+			logger.Error(
+				fmt.Sprintf("Pod annotation format invalid: should be of the format %s/<KEY>", annotation.ConfigPrefix),
+				logfields.ConfigAnnotation, k,
+			)
+			continue
+		}
+		key := s[1]
+		if errs := apivalidation.IsConfigMapKey(key); len(errs) > 0 {
+			logger.Error(
+				"Pod annotation format invalid: invalid key",
+				logfields.Errors, errs,
+				logfields.ConfigKey, k,
+			)
+			continue
+		}
+		out[key] = v
+	}
+	if len(out) == 0 {
+		return nil, nil, nil
+	}
+
+	return out, []ConfigSource{{Kind: KindPod, Namespace: podNamespace, Name: podName}}, nil
+}
+
+// httpSourceTimeout bounds a single fetch from a KindHTTP source, unless the
+// caller-provided http.Client sets a shorter one.
+const httpSourceTimeout = 15 * time.Second
+
+// readHTTPSource fetches configuration from the URL in source.Name. The
+// endpoint is expected to return a flat JSON object of key-value pairs. A 404
+// means the endpoint has no contribution for this node and is not an error.
+// TLS and authentication are the responsibility of the http.Client supplied
+// via WithHTTPClient.
+func readHTTPSource(ctx context.Context, logger *slog.Logger, source ConfigSource, options resolveOptions) (map[string]string, []ConfigSource, error) {
+	httpClient := options.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, httpSourceTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.Name, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config endpoint URL %s: %w", source.Name, err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch config from %s: %w", source.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %s from config endpoint %s", resp.Status, source.Name)
+	}
+
+	var data map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode config from %s: %w", source.Name, err)
+	}
+
+	out := map[string]string{}
+	for k, v := range data {
+		if errs := apivalidation.IsConfigMapKey(k); len(errs) > 0 {
+			logger.Error(
+				"Invalid key from HTTP config source",
+				logfields.Errors, errs,
+				logfields.ConfigKey, k,
+				logfields.URL, source.Name,
+			)
+			continue
+		}
+		out[k] = v
+	}
+	if len(out) == 0 {
+		return nil, nil, nil
+	}
+
+	return out, []ConfigSource{{Kind: KindHTTP, Name: source.Name}}, nil
+}
+
+// readSealedConfigMap reads the sealed blob from the named ConfigMap and
+// decrypts it with the caller-provided unseal function. Keys that are not
+// valid ConfigMap keys after unsealing are logged and dropped, mirroring the
+// HTTP source.
+func readSealedConfigMap(ctx context.Context, logger *slog.Logger, client client.Clientset, source ConfigSource, options resolveOptions) (map[string]string, []ConfigSource, error) {
+	if options.unseal == nil {
+		return nil, nil, fmt.Errorf("source %s requires an unseal function, see WithUnsealFunc", source.String())
+	}
+
+	desc := ConfigSource{Kind: KindSealedConfigMap, Namespace: source.Namespace, Name: source.Name}
 	cm, err := client.CoreV1().ConfigMaps(source.Namespace).Get(ctx, source.Name, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Error(
 				"Configmap not found, ignoring",
-				logfields.ConfigSource, source,
+				logfields.ConfigSource, desc,
 			)
 			return nil, nil, nil
 		}
 		return nil, nil, fmt.Errorf("failed to retrieve ConfigMap %s/%s: %w", source.Namespace, source.Name, err)
 	}
-	if len(cm.Data) == 0 {
+
+	blob, ok := cm.Data[sealedBlobKey]
+	if !ok {
+		return nil, nil, fmt.Errorf("ConfigMap %s/%s has no %q key", source.Namespace, source.Name, sealedBlobKey)
+	}
+
+	data, err := options.unseal([]byte(blob))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unseal config from ConfigMap %s/%s: %w", source.Namespace, source.Name, err)
+	}
+
+	out := map[string]string{}
+	for k, v := range data {
+		if errs := apivalidation.IsConfigMapKey(k); len(errs) > 0 {
+			logger.Error(
+				"Invalid key from sealed config source",
+				logfields.Errors, errs,
+				logfields.ConfigKey, k,
+				logfields.ConfigSource, desc,
+			)
+			continue
+		}
+		out[k] = v
+	}
+	if len(out) == 0 {
 		return nil, nil, nil
 	}
-	return cm.Data, []ConfigSource{source}, nil
+	return out, []ConfigSource{desc}, nil
+}
+
+func readConfigMap(ctx context.Context, logger *slog.Logger, client client.Clientset, source ConfigSource, options resolveOptions) (map[string]string, []ConfigSource, error) {
+	namespaces := source.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{source.Namespace}
+	}
+
+	out := map[string]string{}
+	var sourceDescriptions []ConfigSource
+	for _, namespace := range namespaces {
+		names, err := expandConfigMapNames(ctx, logger, client, namespace, source.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range names {
+			desc := ConfigSource{Kind: KindConfigMap, Namespace: namespace, Name: name}
+			cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					logger.Error(
+						"Configmap not found, ignoring",
+						logfields.ConfigSource, desc,
+					)
+					continue
+				}
+				return nil, nil, fmt.Errorf("failed to retrieve ConfigMap %s/%s: %w", namespace, name, err)
+			}
+			if len(cm.Data) == 0 {
+				continue
+			}
+			out = mergeConfig(logger, options, desc, out, cm.Data)
+			sourceDescriptions = append(sourceDescriptions, desc)
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil, nil
+	}
+	return out, sourceDescriptions, nil
+}
+
+// expandConfigMapNames resolves a ConfigMap name that may contain a glob
+// pattern (filepath.Match syntax) into the matching ConfigMap names in the
+// namespace, in lexicographic order so merge priority is deterministic.
+// Names without glob metacharacters pass through untouched, avoiding a List
+// for the common case.
+func expandConfigMapNames(ctx context.Context, logger *slog.Logger, client client.Clientset, namespace, name string) ([]string, error) {
+	if !strings.ContainsAny(name, "*?[") {
+		return []string{name}, nil
+	}
+
+	cms, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ConfigMaps in %s for pattern %q: %w", namespace, name, err)
+	}
+
+	var names []string
+	for _, cm := range cms.Items {
+		match, err := filepath.Match(name, cm.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ConfigMap name pattern %q: %w", name, err)
+		}
+		if match {
+			names = append(names, cm.Name)
+		}
+	}
+	if len(names) == 0 {
+		logger.Warn(
+			"No ConfigMaps match pattern, ignoring",
+			logfields.Name, name,
+			logfields.K8sNamespace, namespace,
+		)
+	}
+	slices.Sort(names)
+	return names, nil
 }
 
 // readNodeConfigsAllVersions read node configurations for versions v2 and v2alpha1 of CiliumNodeConfig CRD.
+// A version that is not served, returns NotFound or matches nothing simply
+// contributes no configuration; that is not an error. A genuine read error in
+// one version is logged and the other version's contribution is used alone;
+// only when both versions fail is an error returned.
 // TODO depreciate CNC on v2alpha1 https://github.com/cilium/cilium/issues/31982
-func readNodeConfigsAllVersions(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName, namespace, name string) (map[string]string, []ConfigSource, error) {
-	var errv2, errv2alpha1 error
+func readNodeConfigsAllVersions(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName, namespace, name, selector string, priority CNCPriorityMode) (map[string]string, []ConfigSource, error) {
+	nodeConfigv2, descv2, errv2 := readNodeConfigs(ctx, logger, client, nodeName, namespace, name, selector, priority)
+	nodeConfigv2alpha1, descv2alpha1, errv2alpha1 := readNodeConfigsv2alpha1(ctx, logger, client, nodeName, namespace, name, selector, priority)
 
-	nodeConfigv2, descv2, errv2 := readNodeConfigs(ctx, logger, client, nodeName, namespace, name)
+	if errv2 != nil && errv2alpha1 != nil {
+		return nil, nil, fmt.Errorf("could not read CiliumNodeConfig v2 and v2alpha1: %w and %w", errv2, errv2alpha1)
+	}
 	if errv2 != nil {
-		logger.Error(
-			"CiliumNodeConfig v2 not found",
+		logger.Warn(
+			"Could not read CiliumNodeConfig v2, using v2alpha1 only",
 			logfields.Error, errv2,
 			logfields.Node, nodeName,
 		)
 	}
-
-	nodeConfigv2alpha1, descv2alpha1, errv2alpha1 := readNodeConfigsv2alpha1(ctx, logger, client, nodeName, namespace, name)
 	if errv2alpha1 != nil {
-		logger.Error(
-			"CiliumNodeConfig v2alpha1 not found",
+		logger.Warn(
+			"Could not read CiliumNodeConfig v2alpha1, using v2 only",
 			logfields.Error, errv2alpha1,
 			logfields.Node, nodeName,
 		)
-		// return the errors for the two versions
-		if errv2 != nil {
-			return nil, nil, fmt.Errorf("CiliumNodeConfig v2 and v2alpha1 not found: %w and %w\n", errv2, errv2alpha1)
-		}
-		return nil, nil, errv2alpha1
 	}
 
+	// Merge the two contributions, v2 taking precedence over v2alpha1.
 	// Copiying values from a map into a nil map results in a panic, please refer to https://github.com/golang/go/issues/64390
 	if nodeConfigv2alpha1 == nil {
 		nodeConfigv2alpha1 = nodeConfigv2
@@ -290,26 +1241,45 @@ func readNodeConfigsAllVersions(ctx context.Context, logger *slog.Logger, client
 		maps.Copy(nodeConfigv2alpha1, nodeConfigv2)
 	}
 
-	addedSources := sets.New[ConfigSource](descv2...)
+	addedSources := sets.New[string]()
+	for _, source := range descv2 {
+		addedSources.Insert(source.String())
+	}
 	for _, source := range descv2alpha1 {
-		if !addedSources.Has(source) {
+		if !addedSources.Has(source.String()) {
 			descv2 = append(descv2, source)
 		}
-		addedSources.Insert(source)
+		addedSources.Insert(source.String())
 	}
 
 	return nodeConfigv2alpha1, descv2, nil
 }
 
+// cncListOptions builds the list options for retrieving CiliumNodeConfigs,
+// validating the optional label selector on the CNC objects themselves.
+func cncListOptions(selector string) (metav1.ListOptions, error) {
+	if selector == "" {
+		return metav1.ListOptions{}, nil
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return metav1.ListOptions{}, fmt.Errorf("invalid CiliumNodeConfig label selector %q: %w", selector, err)
+	}
+	return metav1.ListOptions{LabelSelector: selector}, nil
+}
+
 // readNodeConfigs reads all the CiliumNodeConfig in v2 objects and returns a flattened map
 // of any key overrides that apply to this node.
 // TODO remove me when CiliumNodeConfig v2alpha1 is deprecated
-func readNodeConfigs(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName, namespace, name string) (map[string]string, []ConfigSource, error) {
+func readNodeConfigs(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName, namespace, name, selector string, priority CNCPriorityMode) (map[string]string, []ConfigSource, error) {
 	var overrides []ciliumv2.CiliumNodeConfig
 
 	// Retrieve CNCs if the name is not provided
 	if name == "" {
-		l, err := client.CiliumV2().CiliumNodeConfigs(namespace).List(ctx, metav1.ListOptions{})
+		listOpts, err := cncListOptions(selector)
+		if err != nil {
+			return nil, nil, err
+		}
+		l, err := client.CiliumV2().CiliumNodeConfigs(namespace).List(ctx, listOpts)
 		if err != nil {
 			if apierrors.IsNotFound(err) { // Tolerate the CRD not existing
 				return nil, nil, nil
@@ -341,6 +1311,13 @@ func readNodeConfigs(ctx context.Context, logger *slog.Logger, client client.Cli
 		return nil, nil, fmt.Errorf("could not get Node %s: %w", nodeName, err)
 	}
 
+	return matchNodeConfigs(logger, node, overrides, name, priority)
+}
+
+// matchNodeConfigs evaluates which of the given CiliumNodeConfigs apply to
+// node and flattens their defaults into an override map. A non-empty name
+// skips the selector evaluation, matching the behavior of a named source.
+func matchNodeConfigs(logger *slog.Logger, node *corev1.Node, overrides []ciliumv2.CiliumNodeConfig, name string, priority CNCPriorityMode) (map[string]string, []ConfigSource, error) {
 	matching := map[string]ciliumv2.CiliumNodeConfig{}
 
 	// track names separately, since we will compute "priority" by lexicographic sort
@@ -368,8 +1345,20 @@ func readNodeConfigs(ctx context.Context, logger *slog.Logger, client client.Cli
 		}
 	}
 
-	// Within overrides, lexicographical ordering determines priority.
-	slices.Sort(matchingNames)
+	// Within overrides, the last name in the sorted order wins: by default
+	// lexicographically, or by creation time (newest last, ties by name)
+	// when creation-time priority is selected.
+	switch priority {
+	case CNCPriorityCreation:
+		slices.SortFunc(matchingNames, func(a, b string) int {
+			if c := matching[a].CreationTimestamp.Time.Compare(matching[b].CreationTimestamp.Time); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+	default:
+		slices.Sort(matchingNames)
+	}
 
 	out := make(map[string]string)
 	for _, name := range matchingNames {
@@ -395,21 +1384,34 @@ func readNodeConfigs(ctx context.Context, logger *slog.Logger, client client.Cli
 
 	var sourceDescriptions []ConfigSource
 	for _, name := range matchingNames {
-		sourceDescriptions = append(sourceDescriptions, ConfigSource{Kind: KindNodeConfig, Namespace: namespace, Name: name})
+		sourceDescriptions = append(sourceDescriptions, ConfigSource{Kind: KindNodeConfig, Namespace: matching[name].Namespace, Name: name})
 	}
 
 	return out, sourceDescriptions, nil
 }
 
+// SimulateNodeConfigs evaluates which of the given CiliumNodeConfigs would
+// apply to node and what override map would result, using the same
+// selector-matching and lexicographic-priority logic as the live
+// KindNodeConfig source, but purely in-memory. This allows previewing a
+// CiliumNodeConfig rollout without touching live cluster state.
+func SimulateNodeConfigs(logger *slog.Logger, node *corev1.Node, cncs []ciliumv2.CiliumNodeConfig) (map[string]string, []ConfigSource, error) {
+	return matchNodeConfigs(logger, node, cncs, "", CNCPriorityName)
+}
+
 // readNodeConfigsv2alpha1 reads all the CiliumNodeConfig in v2alpha1 objects and returns a flattened map
 // of any key overrides that apply to this node.
 // TODO depreciate CNC on v2alpha1 https://github.com/cilium/cilium/issues/31982
-func readNodeConfigsv2alpha1(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName, namespace, name string) (map[string]string, []ConfigSource, error) {
+func readNodeConfigsv2alpha1(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName, namespace, name, selector string, priority CNCPriorityMode) (map[string]string, []ConfigSource, error) {
 	var overrides []ciliumv2alpha1.CiliumNodeConfig
 
 	// Retrieve CNCs if the name is not provided
 	if name == "" {
-		l, err := client.CiliumV2alpha1().CiliumNodeConfigs(namespace).List(ctx, metav1.ListOptions{})
+		listOpts, err := cncListOptions(selector)
+		if err != nil {
+			return nil, nil, err
+		}
+		l, err := client.CiliumV2alpha1().CiliumNodeConfigs(namespace).List(ctx, listOpts)
 		if apierrors.IsNotFound(err) { // Tolerate the CRD not existing
 			return nil, nil, nil
 		}
@@ -467,8 +1469,20 @@ func readNodeConfigsv2alpha1(ctx context.Context, logger *slog.Logger, client cl
 		}
 	}
 
-	// Within overrides, lexicographical ordering determines priority.
-	slices.Sort(matchingNames)
+	// Within overrides, the last name in the sorted order wins: by default
+	// lexicographically, or by creation time (newest last, ties by name)
+	// when creation-time priority is selected.
+	switch priority {
+	case CNCPriorityCreation:
+		slices.SortFunc(matchingNames, func(a, b string) int {
+			if c := matching[a].CreationTimestamp.Time.Compare(matching[b].CreationTimestamp.Time); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+	default:
+		slices.Sort(matchingNames)
+	}
 
 	out := make(map[string]string)
 	for _, name := range matchingNames {
@@ -4,16 +4,33 @@
 package resolver
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/cilium/hive/hivetest"
 	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8sTesting "k8s.io/client-go/testing"
 
 	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
 	ciliumv2alpha1 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2alpha1"
@@ -45,6 +62,39 @@ func TestWriteConfigurations(t *testing.T) {
 	}
 }
 
+func TestReadConfigurations(t *testing.T) {
+	logger := hivetest.Logger(t)
+	dir := t.TempDir()
+
+	out := map[string]string{
+		"A": "a",
+		"B": "b",
+	}
+
+	err := WriteConfigurations(context.Background(), logger, dir, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := ReadConfigurations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(out) {
+		t.Fatalf("Unexpected number of keys, wanted %d got %d", len(out), len(actual))
+	}
+	for k, v := range out {
+		if actual[k] != v {
+			t.Fatalf("Unexpected value for key %s, wanted %s got %s", k, v, actual[k])
+		}
+	}
+
+	// A directory without the ..data symlink is an error
+	if _, err := ReadConfigurations(t.TempDir()); err == nil {
+		t.Fatal("Expected error reading directory without ..data symlink")
+	}
+}
+
 // Test all the various config sources
 // - configmap
 // - node annotations
@@ -266,179 +316,546 @@ func TestWithBlockedFields(t *testing.T) {
 
 }
 
-func TestReadNodeConfigs(t *testing.T) {
-	logger := hivetest.Logger(t)
-	testNS := "test-ns"
+// barrierClientset wraps a Clientset so that ConfigMap reads block until the
+// expected number of them are in flight at the same time. A sequential caller
+// can never satisfy the barrier and times out.
+type barrierClientset struct {
+	k8sClient.Clientset
+	barrier func()
+}
 
-	for _, tc := range []struct {
-		name       string
-		nodeLabels map[string]string
+func (b *barrierClientset) CoreV1() typedcorev1.CoreV1Interface {
+	return &barrierCoreV1{b.Clientset.CoreV1(), b.barrier}
+}
 
-		// can omit namespace + name, will be synthesized with an order
-		confs []ciliumv2.CiliumNodeConfigSpec
+type barrierCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	barrier func()
+}
 
-		expected map[string]string
-	}{
-		{
-			name:       "one-matching",
-			nodeLabels: map[string]string{"a": "b"},
-			confs: []ciliumv2.CiliumNodeConfigSpec{
-				{
-					NodeSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"a": "b",
-						},
-					},
-					Defaults: map[string]string{"key-1": "val-1"},
-				},
+func (b *barrierCoreV1) ConfigMaps(namespace string) typedcorev1.ConfigMapInterface {
+	return &barrierConfigMaps{b.CoreV1Interface.ConfigMaps(namespace), b.barrier}
+}
+
+type barrierConfigMaps struct {
+	typedcorev1.ConfigMapInterface
+	barrier func()
+}
+
+func (b *barrierConfigMaps) Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.ConfigMap, error) {
+	b.barrier()
+	return b.ConfigMapInterface.Get(ctx, name, opts)
+}
+
+func TestResolveConfigurationsConcurrent(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	for i := range 2 {
+		cm := corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNS,
+				Name:      fmt.Sprintf("cm-%d", i),
 			},
-			expected: map[string]string{
-				"key-1": "val-1",
+			Data: map[string]string{
+				fmt.Sprintf("cm-%d-key", i): fmt.Sprintf("cm-%d-val", i),
+				"shared-key":                fmt.Sprintf("cm-%d-val", i),
 			},
+		}
+		_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	sources := []ConfigSource{
+		{Kind: KindConfigMap, Namespace: testNS, Name: "cm-0"},
+		{Kind: KindConfigMap, Namespace: testNS, Name: "cm-1"},
+	}
+
+	sequential, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+
+	// Require both ConfigMap reads to be in flight at the same time before
+	// letting either complete.
+	var arrived atomic.Int32
+	release := make(chan struct{})
+	blocking := &barrierClientset{Clientset: clients, barrier: func() {
+		if arrived.Add(1) == 2 {
+			close(release)
+		}
+		select {
+		case <-release:
+		case <-time.After(10 * time.Second):
+			t.Error("timed out waiting for a concurrent read")
+		}
+	}}
+
+	concurrent, err := ResolveConfigurations(context.Background(), logger, blocking, "nodename", sources, nil, nil, WithConcurrentReads(2))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(concurrent).To(gomega.Equal(sequential))
+	g.Expect(concurrent).To(gomega.HaveKeyWithValue("shared-key", "cm-1-val"))
+}
+
+func TestResolveConfigurationsNormalizedKeys(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "cm",
 		},
-		{
-			name:       "none-matching",
-			nodeLabels: map[string]string{"a": "b"},
-			confs: []ciliumv2.CiliumNodeConfigSpec{
-				{
-					NodeSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"a": "c",
-						},
-					},
-					Defaults: map[string]string{"key-1": "val-1"},
-				},
-			},
-			expected: map[string]string{},
+		Data: map[string]string{
+			" cm-key ":  "cm-val",
+			"Upper-Key": "upper-val",
 		},
+	}
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	sources := []ConfigSource{
 		{
-			name:       "two-matching",
-			nodeLabels: map[string]string{"a": "b"},
-			confs: []ciliumv2.CiliumNodeConfigSpec{
-				{
-					NodeSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"a": "b",
-						},
-					},
-					Defaults: map[string]string{
-						"key-1": "val-1",
-						"key-2": "val-2",
-					},
-				},
-				{
-					NodeSelector: &metav1.LabelSelector{}, // empty selector, matches all
-					Defaults: map[string]string{
-						"key-1": "overridden",
-						"key-3": "val-3",
-					},
-				},
-				{
-					NodeSelector: nil, // selects nothing
-					Defaults: map[string]string{
-						"key-4": "val-4",
-					},
-				},
-			},
-			expected: map[string]string{
-				"key-1": "overridden",
-				"key-2": "val-2",
-				"key-3": "val-3",
-			},
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "cm",
 		},
-	} {
-		t.Run(tc.name, func(t *testing.T) {
-			g := gomega.NewWithT(t)
+	}
 
-			clients, _ := k8sClient.NewFakeClientset(logger)
+	// Without normalization, keys are passed through untouched
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKey(" cm-key "))
 
-			fakeNode := corev1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:   tc.name,
-					Labels: tc.nodeLabels,
-				},
-			}
-			_, err := clients.CoreV1().Nodes().Create(context.Background(), &fakeNode, metav1.CreateOptions{})
-			g.Expect(err).To(gomega.BeNil())
+	// With normalization, whitespace is trimmed and keys are lowercased
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil, WithNormalizedKeys(true))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("cm-key", "cm-val"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("upper-key", "upper-val"))
+	g.Expect(config).ToNot(gomega.HaveKey(" cm-key "))
+}
 
-			for i, conf := range tc.confs {
-				cnc := ciliumv2.CiliumNodeConfig{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      fmt.Sprintf("cco-%d", i),
-						Namespace: testNS,
-					},
-					Spec: conf,
-				}
-				_, err := clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &cnc, metav1.CreateOptions{})
-				g.Expect(err).To(gomega.BeNil())
-			}
+func TestNormalizeConfigKeysCollision(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+	source := ConfigSource{Kind: KindConfigMap, Namespace: "test-ns", Name: "cm"}
 
-			configs, _, err := readNodeConfigsAllVersions(context.Background(), logger, clients, tc.name, testNS, "")
-			g.Expect(err).To(gomega.BeNil())
+	// Two distinct keys normalizing to the same key is an error
+	_, err := normalizeConfigKeys(logger, source, map[string]string{
+		"key-1":  "val-1",
+		" key-1": "val-2",
+	}, false)
+	g.Expect(err).To(gomega.HaveOccurred())
 
-			g.Expect(configs).To(gomega.Equal(tc.expected))
-		})
-	}
+	// Lowercasing can also introduce collisions
+	_, err = normalizeConfigKeys(logger, source, map[string]string{
+		"key-1": "val-1",
+		"Key-1": "val-2",
+	}, true)
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	// Without lowercasing, differing case is not a collision
+	out, err := normalizeConfigKeys(logger, source, map[string]string{
+		"key-1": "val-1",
+		"Key-1": "val-2",
+	}, false)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(out).To(gomega.HaveLen(2))
 }
 
-// TODO remove me when CiliumNodeConfig v2alpha1 will be deprecated
-func TestReadNodeConfigsAlpha(t *testing.T) {
+func TestResolveConfigurationsWithDefaults(t *testing.T) {
 	logger := hivetest.Logger(t)
 	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
 
-	for _, tc := range []struct {
-		name       string
-		nodeLabels map[string]string
-
-		// can omit namespace + name, will be synthesized with an order
-		confs []ciliumv2alpha1.CiliumNodeConfigSpec
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "cm",
+		},
+		Data: map[string]string{
+			"cm-key": "cm-val",
+		},
+	}
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
 
-		expected map[string]string
-	}{
+	sources := []ConfigSource{
 		{
-			name:       "one-matching",
-			nodeLabels: map[string]string{"a": "b"},
-			confs: []ciliumv2alpha1.CiliumNodeConfigSpec{
-				{
-					NodeSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"a": "b",
-						},
-					},
-					Defaults: map[string]string{"key-1": "val-1"},
-				},
-			},
-			expected: map[string]string{
-				"key-1": "val-1",
-			},
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "cm",
 		},
-		{
-			name:       "none-matching",
-			nodeLabels: map[string]string{"a": "b"},
-			confs: []ciliumv2alpha1.CiliumNodeConfigSpec{
-				{
-					NodeSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"a": "c",
-						},
-					},
-					Defaults: map[string]string{"key-1": "val-1"},
-				},
-			},
-			expected: map[string]string{},
+	}
+
+	// A default not set by any source survives and is recorded in the
+	// provenance list as the lowest-priority source.
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil,
+		WithDefaults(map[string]string{"default-key": "default-val"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("default-key", "default-val"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cm-key", "cm-val"))
+	g.Expect(config[ConfigSources]).To(gomega.Equal(
+		"[{\"kind\":\"default\",\"namespace\":\"\",\"name\":\"\"},{\"kind\":\"config-map\",\"namespace\":\"test-ns\",\"name\":\"cm\"}]"))
+
+	// A default for a key a source sets is overridden and leaves no
+	// provenance entry.
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil,
+		WithDefaults(map[string]string{"cm-key": "default-val"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("cm-key", "cm-val"))
+	g.Expect(config[ConfigSources]).To(gomega.Equal(
+		"[{\"kind\":\"config-map\",\"namespace\":\"test-ns\",\"name\":\"cm\"}]"))
+}
+
+func TestResolveConfigurationsNamespaceList(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	clusterCM := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "cluster-ns",
+			Name:      "cm",
 		},
-		{
-			name:       "two-matching",
-			nodeLabels: map[string]string{"a": "b"},
-			confs: []ciliumv2alpha1.CiliumNodeConfigSpec{
-				{
-					NodeSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"a": "b",
-						},
-					},
-					Defaults: map[string]string{
-						"key-1": "val-1",
+		Data: map[string]string{
+			"cluster-key": "cluster-val",
+			"shared-key":  "cluster-val",
+		},
+	}
+	_, err := clients.CoreV1().ConfigMaps("cluster-ns").Create(context.Background(), &clusterCM, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	teamCM := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-ns",
+			Name:      "cm",
+		},
+		Data: map[string]string{
+			"team-key":   "team-val",
+			"shared-key": "team-val",
+		},
+	}
+	_, err = clients.CoreV1().ConfigMaps("team-ns").Create(context.Background(), &teamCM, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	// Later namespaces override earlier ones, and a namespace without the
+	// ConfigMap (missing-ns) is skipped.
+	sources := []ConfigSource{
+		{
+			Kind:       KindConfigMap,
+			Name:       "cm",
+			Namespaces: []string{"cluster-ns", "missing-ns", "team-ns"},
+		},
+	}
+
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.Equal(map[string]string{
+		"cluster-key":              "cluster-val",
+		"team-key":                 "team-val",
+		"shared-key":               "team-val",
+		"config-sources":           "[{\"kind\":\"config-map\",\"namespace\":\"cluster-ns\",\"name\":\"cm\"},{\"kind\":\"config-map\",\"namespace\":\"team-ns\",\"name\":\"cm\"}]",
+		"config-sources-overrides": "{\"allowConfigKeys\":null,\"denyConfigKeys\":null}",
+	}))
+
+	// Fallthrough: only the last namespace has the ConfigMap.
+	sources[0].Namespaces = []string{"missing-ns", "team-ns"}
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("shared-key", "team-val"))
+	g.Expect(config).ToNot(gomega.HaveKey("cluster-key"))
+	g.Expect(config[ConfigSources]).To(gomega.Equal(
+		"[{\"kind\":\"config-map\",\"namespace\":\"team-ns\",\"name\":\"cm\"}]"))
+}
+
+func TestResolveConfigurationsImmutableKeys(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "trusted",
+		},
+		Data: map[string]string{
+			"pinned-key":  "trusted-val",
+			"regular-key": "trusted-val",
+		},
+	}
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	fakeNode := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nodename",
+			Annotations: map[string]string{
+				"config.cilium.io/pinned-key":  "anno-val",
+				"config.cilium.io/regular-key": "anno-val",
+			},
+		},
+	}
+	_, err = clients.CoreV1().Nodes().Create(context.Background(), &fakeNode, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	sources := []ConfigSource{
+		{
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "trusted",
+		},
+		{
+			Kind: KindNode,
+			Name: "nodename",
+		},
+	}
+
+	// The Node annotation may still override regular-key, but the immutable
+	// pinned-key stays with the ConfigMap that set it first.
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil,
+		WithImmutableKeys([]string{"pinned-key"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("pinned-key", "trusted-val"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("regular-key", "anno-val"))
+}
+
+func TestResolveConfigurationsValueTransforms(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "cm",
+		},
+		Data: map[string]string{
+			"bool-key":  "True",
+			"other-key": "other-val\n",
+		},
+	}
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	sources := []ConfigSource{
+		{
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "cm",
+		},
+	}
+
+	// A per-key transform only touches its key
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil,
+		WithValueTransforms(map[string]func(string) string{
+			"bool-key": strings.ToLower,
+		}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("bool-key", "true"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("other-key", "other-val\n"))
+
+	// A wildcard transform applies to all keys
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil,
+		WithValueTransforms(map[string]func(string) string{
+			"*": strings.TrimSpace,
+		}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("other-key", "other-val"))
+}
+
+func TestResolveConfigurationsSummaryLog(t *testing.T) {
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+
+	var logBuf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logBuf, nil))
+	clients, _ := k8sClient.NewFakeClientset(hivetest.Logger(t))
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "cm",
+		},
+		Data: map[string]string{
+			"cm-key": "cm-val",
+		},
+	}
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	// One source is present (listed twice), the other ConfigMap does not
+	// exist. The duplicate listing counts as a single used source.
+	sources := []ConfigSource{
+		{
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "cm",
+		},
+		{
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "cm",
+		},
+		{
+			Kind:      KindConfigMap,
+			Namespace: testNS,
+			Name:      "absent",
+		},
+	}
+
+	_, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(logBuf.String()).To(gomega.ContainSubstring("Resolved configuration"))
+	g.Expect(logBuf.String()).To(gomega.ContainSubstring("sourcesProvided=3"))
+	g.Expect(logBuf.String()).To(gomega.ContainSubstring("sourcesUsed=1"))
+	g.Expect(logBuf.String()).To(gomega.ContainSubstring("keysResolved=1"))
+}
+
+func TestResolveConfigurationsCNCCreationPriority(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	fakeNode := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "nodename",
+			Labels: map[string]string{"a": "b"},
+		},
+	}
+	_, err := clients.CoreV1().Nodes().Create(context.Background(), &fakeNode, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	// The lexicographically last CNC is the older one, so the two priority
+	// modes pick different winners.
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, cnc := range []ciliumv2.CiliumNodeConfig{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         testNS,
+				Name:              "z-older",
+				CreationTimestamp: metav1.NewTime(base),
+			},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				Defaults:     map[string]string{"shared-key": "older-val"},
+				NodeSelector: &metav1.LabelSelector{},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         testNS,
+				Name:              "a-newer",
+				CreationTimestamp: metav1.NewTime(base.Add(time.Hour)),
+			},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				Defaults:     map[string]string{"shared-key": "newer-val"},
+				NodeSelector: &metav1.LabelSelector{},
+			},
+		},
+	} {
+		_, err := clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &cnc, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	sources := []ConfigSource{
+		{
+			Kind:      KindNodeConfig,
+			Namespace: testNS,
+		},
+	}
+
+	// Default: lexicographic priority, z-older wins
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("shared-key", "older-val"))
+
+	// Creation-time priority: the newest CNC wins
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", sources, nil, nil,
+		WithCNCPriority(CNCPriorityCreation))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("shared-key", "newer-val"))
+}
+
+func TestConfigOverrideValidate(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	// Neither list set
+	g.Expect(ConfigOverride{}.Validate()).To(gomega.BeNil())
+
+	// Allow-only
+	g.Expect(ConfigOverride{AllowConfigKeys: []string{"a"}}.Validate()).To(gomega.BeNil())
+
+	// Deny-only
+	g.Expect(ConfigOverride{DenyConfigKeys: []string{"a"}}.Validate()).To(gomega.BeNil())
+
+	// Both set: deny is silently ignored, so Validate surfaces the conflict
+	err := ConfigOverride{AllowConfigKeys: []string{"a"}, DenyConfigKeys: []string{"b"}}.Validate()
+	g.Expect(err).To(gomega.MatchError(ErrAllowDenyConflict))
+}
+
+func TestReadNodeConfigs(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+
+	for _, tc := range []struct {
+		name       string
+		nodeLabels map[string]string
+
+		// can omit namespace + name, will be synthesized with an order
+		confs []ciliumv2.CiliumNodeConfigSpec
+
+		expected map[string]string
+	}{
+		{
+			name:       "one-matching",
+			nodeLabels: map[string]string{"a": "b"},
+			confs: []ciliumv2.CiliumNodeConfigSpec{
+				{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"a": "b",
+						},
+					},
+					Defaults: map[string]string{"key-1": "val-1"},
+				},
+			},
+			expected: map[string]string{
+				"key-1": "val-1",
+			},
+		},
+		{
+			name:       "none-matching",
+			nodeLabels: map[string]string{"a": "b"},
+			confs: []ciliumv2.CiliumNodeConfigSpec{
+				{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"a": "c",
+						},
+					},
+					Defaults: map[string]string{"key-1": "val-1"},
+				},
+			},
+			expected: map[string]string{},
+		},
+		{
+			name:       "two-matching",
+			nodeLabels: map[string]string{"a": "b"},
+			confs: []ciliumv2.CiliumNodeConfigSpec{
+				{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"a": "b",
+						},
+					},
+					Defaults: map[string]string{
+						"key-1": "val-1",
 						"key-2": "val-2",
 					},
 				},
@@ -478,22 +895,1300 @@ func TestReadNodeConfigsAlpha(t *testing.T) {
 			g.Expect(err).To(gomega.BeNil())
 
 			for i, conf := range tc.confs {
-				cnc := ciliumv2alpha1.CiliumNodeConfig{
+				cnc := ciliumv2.CiliumNodeConfig{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      fmt.Sprintf("cco-%d", i),
 						Namespace: testNS,
 					},
 					Spec: conf,
 				}
-				_, err := clients.CiliumV2alpha1().CiliumNodeConfigs(testNS).Create(context.Background(), &cnc, metav1.CreateOptions{})
+				_, err := clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &cnc, metav1.CreateOptions{})
 				g.Expect(err).To(gomega.BeNil())
 			}
 
-			configs, _, err := readNodeConfigsAllVersions(context.Background(), logger, clients, tc.name, testNS, "")
+			configs, _, err := readNodeConfigsAllVersions(context.Background(), logger, clients, tc.name, testNS, "", "", CNCPriorityName)
 			g.Expect(err).To(gomega.BeNil())
 
 			g.Expect(configs).To(gomega.Equal(tc.expected))
-
 		})
 	}
 }
+
+// TODO remove me when CiliumNodeConfig v2alpha1 will be deprecated
+func TestReadNodeConfigsAlpha(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+
+	for _, tc := range []struct {
+		name       string
+		nodeLabels map[string]string
+
+		// can omit namespace + name, will be synthesized with an order
+		confs []ciliumv2alpha1.CiliumNodeConfigSpec
+
+		expected map[string]string
+	}{
+		{
+			name:       "one-matching",
+			nodeLabels: map[string]string{"a": "b"},
+			confs: []ciliumv2alpha1.CiliumNodeConfigSpec{
+				{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"a": "b",
+						},
+					},
+					Defaults: map[string]string{"key-1": "val-1"},
+				},
+			},
+			expected: map[string]string{
+				"key-1": "val-1",
+			},
+		},
+		{
+			name:       "none-matching",
+			nodeLabels: map[string]string{"a": "b"},
+			confs: []ciliumv2alpha1.CiliumNodeConfigSpec{
+				{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"a": "c",
+						},
+					},
+					Defaults: map[string]string{"key-1": "val-1"},
+				},
+			},
+			expected: map[string]string{},
+		},
+		{
+			name:       "two-matching",
+			nodeLabels: map[string]string{"a": "b"},
+			confs: []ciliumv2alpha1.CiliumNodeConfigSpec{
+				{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"a": "b",
+						},
+					},
+					Defaults: map[string]string{
+						"key-1": "val-1",
+						"key-2": "val-2",
+					},
+				},
+				{
+					NodeSelector: &metav1.LabelSelector{}, // empty selector, matches all
+					Defaults: map[string]string{
+						"key-1": "overridden",
+						"key-3": "val-3",
+					},
+				},
+				{
+					NodeSelector: nil, // selects nothing
+					Defaults: map[string]string{
+						"key-4": "val-4",
+					},
+				},
+			},
+			expected: map[string]string{
+				"key-1": "overridden",
+				"key-2": "val-2",
+				"key-3": "val-3",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			clients, _ := k8sClient.NewFakeClientset(logger)
+
+			fakeNode := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   tc.name,
+					Labels: tc.nodeLabels,
+				},
+			}
+			_, err := clients.CoreV1().Nodes().Create(context.Background(), &fakeNode, metav1.CreateOptions{})
+			g.Expect(err).To(gomega.BeNil())
+
+			for i, conf := range tc.confs {
+				cnc := ciliumv2alpha1.CiliumNodeConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("cco-%d", i),
+						Namespace: testNS,
+					},
+					Spec: conf,
+				}
+				_, err := clients.CiliumV2alpha1().CiliumNodeConfigs(testNS).Create(context.Background(), &cnc, metav1.CreateOptions{})
+				g.Expect(err).To(gomega.BeNil())
+			}
+
+			configs, _, err := readNodeConfigsAllVersions(context.Background(), logger, clients, tc.name, testNS, "", "", CNCPriorityName)
+			g.Expect(err).To(gomega.BeNil())
+
+			g.Expect(configs).To(gomega.Equal(tc.expected))
+
+		})
+	}
+}
+
+func TestReadPodOverrides(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	fakePod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "cilium-abc12",
+			Annotations: map[string]string{
+				"config.cilium.io/pod-key": "pod-val",
+				"config.cilium.io/":        "invalid-key",
+				"other.io/unrelated":       "ignored",
+			},
+		},
+	}
+	_, err := clients.CoreV1().Pods(testNS).Create(context.Background(), &fakePod, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	config, err := ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{
+			{
+				Kind:      KindPod,
+				Namespace: testNS,
+				Name:      "cilium-abc12",
+			},
+		}, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["pod-key"]).To(gomega.Equal("pod-val"))
+	g.Expect(config).ToNot(gomega.HaveKey(""))
+	g.Expect(config).ToNot(gomega.HaveKey("unrelated"))
+	g.Expect(config[ConfigSources]).To(gomega.Equal(`[{"kind":"pod","namespace":"test-ns","name":"cilium-abc12"}]`))
+
+	// A pod source without a name or downward-API fallback is an error.
+	t.Setenv(podNameEnv, "")
+	_, _, err = readPodOverrides(context.Background(), logger, clients, ConfigSource{Kind: KindPod, Namespace: testNS})
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	// With the downward-API environment set, the source fields may be empty.
+	t.Setenv(podNameEnv, "cilium-abc12")
+	t.Setenv(podNamespaceEnv, testNS)
+	overrides, _, err := readPodOverrides(context.Background(), logger, clients, ConfigSource{Kind: KindPod})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(overrides).To(gomega.Equal(map[string]string{"pod-key": "pod-val"}))
+}
+
+// Test the interplay between the v2 and v2alpha1 CiliumNodeConfig reads:
+// an absent version contributes nothing, and only both versions failing
+// is an error.
+func TestReadNodeConfigsAllVersions(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+
+	newClients := func(g *gomega.WithT) *k8sClient.FakeClientset {
+		clients, _ := k8sClient.NewFakeClientset(logger)
+		_, err := clients.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodename"},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		return clients
+	}
+
+	t.Run("both absent", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		clients := newClients(g)
+
+		config, sources, err := readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "missing", "", CNCPriorityName)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(config).To(gomega.BeEmpty())
+		g.Expect(sources).To(gomega.BeEmpty())
+	})
+
+	t.Run("v2 present, v2alpha1 absent", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		clients := newClients(g)
+
+		_, err := clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &ciliumv2.CiliumNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "only-v2"},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				Defaults: map[string]string{"key": "v2-val"},
+			},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		config, sources, err := readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "only-v2", "", CNCPriorityName)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(config).To(gomega.Equal(map[string]string{"key": "v2-val"}))
+		g.Expect(sources).To(gomega.HaveLen(1))
+	})
+
+	t.Run("both present", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		clients := newClients(g)
+
+		_, err := clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &ciliumv2.CiliumNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "both"},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				Defaults: map[string]string{"key": "v2-val"},
+			},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		_, err = clients.CiliumV2alpha1().CiliumNodeConfigs(testNS).Create(context.Background(), &ciliumv2alpha1.CiliumNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "both"},
+			Spec: ciliumv2alpha1.CiliumNodeConfigSpec{
+				Defaults: map[string]string{"key": "alpha-val", "alpha-key": "alpha-only"},
+			},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		config, sources, err := readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "both", "", CNCPriorityName)
+		g.Expect(err).To(gomega.BeNil())
+		// v2 wins for shared keys, v2alpha1-only keys survive, and the
+		// identical source description is not duplicated.
+		g.Expect(config).To(gomega.Equal(map[string]string{"key": "v2-val", "alpha-key": "alpha-only"}))
+		g.Expect(sources).To(gomega.HaveLen(1))
+	})
+
+	t.Run("v2 error, v2alpha1 present", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		clients := newClients(g)
+
+		clients.CiliumFakeClientset.PrependReactor("get", "ciliumnodeconfigs", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+			if action.GetResource().Version == "v2" {
+				return true, nil, fmt.Errorf("injected v2 failure")
+			}
+			return false, nil, nil
+		})
+
+		_, err := clients.CiliumV2alpha1().CiliumNodeConfigs(testNS).Create(context.Background(), &ciliumv2alpha1.CiliumNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "fallback"},
+			Spec: ciliumv2alpha1.CiliumNodeConfigSpec{
+				Defaults: map[string]string{"key": "alpha-val"},
+			},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		config, sources, err := readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "fallback", "", CNCPriorityName)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(config).To(gomega.Equal(map[string]string{"key": "alpha-val"}))
+		g.Expect(sources).To(gomega.HaveLen(1))
+	})
+}
+
+func TestWriteConfigurationsEnvFile(t *testing.T) {
+	g := gomega.NewWithT(t)
+	dest := filepath.Join(t.TempDir(), "cilium.env")
+
+	g.Expect(WriteConfigurationsEnvFile(dest, map[string]string{
+		"enable-ipv6":  "true",
+		"cluster-name": "my cluster",
+		"multi-line":   "a\nb",
+		"debug":        "false",
+	})).To(gomega.BeNil())
+
+	raw, err := os.ReadFile(dest)
+	g.Expect(err).To(gomega.BeNil())
+
+	// Sorted by key, keys uppercased with hyphens converted to underscores,
+	// and values with special characters quoted.
+	g.Expect(string(raw)).To(gomega.Equal(
+		"CLUSTER_NAME=\"my cluster\"\n" +
+			"DEBUG=false\n" +
+			"ENABLE_IPV6=true\n" +
+			"MULTI_LINE=\"a\\nb\"\n"))
+
+	// No leftover temp file from the atomic write.
+	_, err = os.Stat(dest + ".tmp")
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+}
+
+func TestWriteConfigurationsMulti(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+
+	data := map[string]string{"key": "new"}
+
+	// Success path: every directory ends up with the data.
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	g.Expect(WriteConfigurationsMulti(context.Background(), logger, []string{dir1, dir2}, data)).To(gomega.BeNil())
+	for _, dir := range []string{dir1, dir2} {
+		read, err := ReadConfigurations(dir)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(read["key"]).To(gomega.Equal("new"))
+	}
+
+	// Pre-populate a directory with an older generation, laid out by hand so
+	// its data directory name cannot collide with the one a new write picks.
+	dir3 := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(dir3, "..data_old"), 0777)).To(gomega.BeNil())
+	g.Expect(os.WriteFile(filepath.Join(dir3, "..data_old", "key"), []byte("old"), 0644)).To(gomega.BeNil())
+	g.Expect(os.Symlink("..data_old", filepath.Join(dir3, "..data"))).To(gomega.BeNil())
+	g.Expect(os.Symlink(filepath.Join("..data", "key"), filepath.Join(dir3, "key"))).To(gomega.BeNil())
+
+	// A regular file is not a usable destination, so the second write fails
+	// and the first directory is rolled back to the old generation.
+	badDest := filepath.Join(t.TempDir(), "not-a-dir")
+	g.Expect(os.WriteFile(badDest, nil, 0644)).To(gomega.BeNil())
+
+	err := WriteConfigurationsMulti(context.Background(), logger, []string{dir3, badDest}, data)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring(badDest))
+
+	read, err := ReadConfigurations(dir3)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(read["key"]).To(gomega.Equal("old"))
+}
+
+func TestWriteConfigurationsSealedKeys(t *testing.T) {
+	logger := hivetest.Logger(t)
+	dir := t.TempDir()
+	g := gomega.NewWithT(t)
+
+	// A toy sealing scheme; real callers plug in proper encryption.
+	seal := func(value string) (string, error) {
+		return "sealed(" + value + ")", nil
+	}
+	unseal := func(value string) (string, error) {
+		inner, found := strings.CutPrefix(value, "sealed(")
+		if !found {
+			return "", fmt.Errorf("value is not sealed")
+		}
+		return strings.TrimSuffix(inner, ")"), nil
+	}
+
+	data := map[string]string{
+		"plain-key":  "plain-val",
+		"secret-key": "secret-val",
+	}
+	err := WriteConfigurations(context.Background(), logger, dir, data, WithSealedKeys([]string{"secret-key"}, seal))
+	g.Expect(err).To(gomega.BeNil())
+
+	// On disk, only the sensitive key is sealed.
+	onDisk, err := os.ReadFile(filepath.Join(dir, "plain-key"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(onDisk)).To(gomega.Equal("plain-val"))
+	onDisk, err = os.ReadFile(filepath.Join(dir, "secret-key"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(onDisk)).To(gomega.Equal("sealed(secret-val)"))
+
+	// Reading back with the matching unseal round-trips the original data.
+	actual, err := ReadConfigurations(dir, WithUnsealedKeys([]string{"secret-key"}, unseal))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(actual).To(gomega.Equal(data))
+
+	// An unseal failure is surfaced rather than returning ciphertext.
+	_, err = ReadConfigurations(dir, WithUnsealedKeys([]string{"plain-key"}, unseal))
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestResolveConfigurationsOverrideLogLevel(t *testing.T) {
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+
+	resolve := func(logger *slog.Logger, opts ...ResolveOption) {
+		clients, _ := k8sClient.NewFakeClientset(logger)
+		for i, name := range []string{"cm-a", "cm-b"} {
+			_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: name},
+				Data:       map[string]string{"shared-key": fmt.Sprintf("val-%d", i)},
+			}, metav1.CreateOptions{})
+			g.Expect(err).To(gomega.BeNil())
+		}
+
+		_, err := ResolveConfigurations(
+			context.Background(), logger, clients, "nodename",
+			[]ConfigSource{
+				{Kind: KindConfigMap, Namespace: testNS, Name: "cm-a"},
+				{Kind: KindConfigMap, Namespace: testNS, Name: "cm-b"},
+			}, []string{"shared-key"}, nil, opts...)
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	// At the default setting, the per-key override message is emitted at info.
+	var buf bytes.Buffer
+	resolve(slog.New(slog.NewTextHandler(&buf, nil)))
+	g.Expect(buf.String()).To(gomega.ContainSubstring("Source overrides key"))
+
+	// Demoted to debug, it disappears from an info-level logger.
+	buf.Reset()
+	resolve(slog.New(slog.NewTextHandler(&buf, nil)), WithOverrideLogLevel(slog.LevelDebug))
+	g.Expect(buf.String()).ToNot(gomega.ContainSubstring("Source overrides key"))
+}
+
+func TestSimulateNodeConfigs(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodename", Labels: map[string]string{"tier": "prod"}},
+	}
+	cncs := []ciliumv2.CiliumNodeConfig{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "cnc-a"},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "prod"}},
+				Defaults:     map[string]string{"shared": "from-a", "only-a": "1"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "cnc-b"},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "prod"}},
+				Defaults:     map[string]string{"shared": "from-b"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "cnc-other"},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "dev"}},
+				Defaults:     map[string]string{"unrelated": "x"},
+			},
+		},
+	}
+
+	simConfig, simSources, err := SimulateNodeConfigs(logger, node, cncs)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(simConfig).To(gomega.Equal(map[string]string{"shared": "from-b", "only-a": "1"}))
+	g.Expect(simSources).To(gomega.HaveLen(2))
+
+	// The simulation matches what the live path resolves for the same inputs.
+	clients, _ := k8sClient.NewFakeClientset(logger)
+	_, err = clients.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	for i := range cncs {
+		_, err = clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &cncs[i], metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	liveConfig, liveSources, err := readNodeConfigs(context.Background(), logger, clients, "nodename", testNS, "", "", CNCPriorityName)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(simConfig).To(gomega.Equal(liveConfig))
+	g.Expect(simSources).To(gomega.Equal(liveSources))
+}
+
+func TestReadConfigSourceKeyAllowlist(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	// A shared ConfigMap carrying both Cilium config and unrelated keys.
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "shared-cm"},
+		Data: map[string]string{
+			"wanted-a":  "1",
+			"wanted-b":  "2",
+			"unrelated": "3",
+		},
+	}, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	config, _, err := ReadConfigSource(context.Background(), logger, clients, "nodename",
+		ConfigSource{Kind: KindConfigMap, Namespace: testNS, Name: "shared-cm", Keys: []string{"wanted-a", "wanted-b"}})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.Equal(map[string]string{"wanted-a": "1", "wanted-b": "2"}))
+
+	// The filtered view is what the merge sees.
+	merged, err := ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{{Kind: KindConfigMap, Namespace: testNS, Name: "shared-cm", Keys: []string{"wanted-a"}}}, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(merged["wanted-a"]).To(gomega.Equal("1"))
+	g.Expect(merged).ToNot(gomega.HaveKey("unrelated"))
+	g.Expect(merged).ToNot(gomega.HaveKey("wanted-b"))
+}
+
+func TestReadConfigSourceStripKeyPrefixes(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	// A shared ConfigMap where our keys carry a namespacing prefix.
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "prefixed-cm"},
+		Data: map[string]string{
+			"acme.config/bpf-lb-mode": "dsr",
+			"acme.config/debug":       "true",
+			"bare-key":                "untouched",
+		},
+	}, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	config, _, err := ReadConfigSource(context.Background(), logger, clients, "nodename",
+		ConfigSource{Kind: KindConfigMap, Namespace: testNS, Name: "prefixed-cm", StripKeyPrefixes: []string{"acme.config/"}})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.Equal(map[string]string{
+		"bpf-lb-mode": "dsr",
+		"debug":       "true",
+		"bare-key":    "untouched",
+	}))
+
+	// Two keys stripping to the same bare key is an error, not a silent
+	// overwrite.
+	_, err = clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "colliding-cm"},
+		Data: map[string]string{
+			"acme.config/debug": "true",
+			"debug":             "false",
+		},
+	}, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	_, _, err = ReadConfigSource(context.Background(), logger, clients, "nodename",
+		ConfigSource{Kind: KindConfigMap, Namespace: testNS, Name: "colliding-cm", StripKeyPrefixes: []string{"acme.config/"}})
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("strip to \"debug\"")))
+}
+
+func TestResolveConfigurationsIgnoreEmptyValues(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+
+	resolve := func(opts ...ResolveOption) map[string]string {
+		clients, _ := k8sClient.NewFakeClientset(logger)
+		for _, cm := range []struct {
+			name string
+			data map[string]string
+		}{
+			{"cm-a", map[string]string{"keep-me": "lower-val", "clobber-me": "lower-val"}},
+			{"cm-b", map[string]string{"keep-me": "", "clobber-me": ""}},
+		} {
+			_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: cm.name},
+				Data:       cm.data,
+			}, metav1.CreateOptions{})
+			g.Expect(err).To(gomega.BeNil())
+		}
+
+		config, err := ResolveConfigurations(
+			context.Background(), logger, clients, "nodename",
+			[]ConfigSource{
+				{Kind: KindConfigMap, Namespace: testNS, Name: "cm-a"},
+				{Kind: KindConfigMap, Namespace: testNS, Name: "cm-b"},
+			}, nil, nil, opts...)
+		g.Expect(err).To(gomega.BeNil())
+		return config
+	}
+
+	// Default: the empty higher-priority value clobbers the lower one.
+	config := resolve()
+	g.Expect(config["keep-me"]).To(gomega.Equal(""))
+	g.Expect(config["clobber-me"]).To(gomega.Equal(""))
+
+	// Per-key policy: only the listed key keeps its non-empty value.
+	config = resolve(WithIgnoreEmptyValues("keep-me"))
+	g.Expect(config["keep-me"]).To(gomega.Equal("lower-val"))
+	g.Expect(config["clobber-me"]).To(gomega.Equal(""))
+
+	// Global policy: empty values never override non-empty ones.
+	config = resolve(WithIgnoreEmptyValues())
+	g.Expect(config["keep-me"]).To(gomega.Equal("lower-val"))
+	g.Expect(config["clobber-me"]).To(gomega.Equal("lower-val"))
+}
+
+func TestResolveConfigurationsRequiredKeys(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+
+	resolve := func(opts ...ResolveOption) (map[string]string, error) {
+		clients, _ := k8sClient.NewFakeClientset(logger)
+		_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "cm-a"},
+			Data:       map[string]string{"supplied-key": "from-source"},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		return ResolveConfigurations(
+			context.Background(), logger, clients, "nodename",
+			[]ConfigSource{
+				{Kind: KindConfigMap, Namespace: testNS, Name: "cm-a"},
+			}, nil, nil, opts...)
+	}
+
+	// Source-supplied: the fallback is ignored and no synthetic source is
+	// recorded.
+	config, err := resolve(WithRequiredKeys([]string{"supplied-key"}, map[string]string{"supplied-key": "from-fallback"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["supplied-key"]).To(gomega.Equal("from-source"))
+	g.Expect(config[ConfigSources]).NotTo(gomega.ContainSubstring(KindFallback))
+
+	// Fallback-supplied: the key is filled in and attributed to the
+	// synthetic fallback source.
+	config, err = resolve(WithRequiredKeys([]string{"missing-key"}, map[string]string{"missing-key": "from-fallback"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["missing-key"]).To(gomega.Equal("from-fallback"))
+	g.Expect(config[ConfigSources]).To(gomega.ContainSubstring(KindFallback))
+
+	// Neither: resolution fails.
+	_, err = resolve(WithRequiredKeys([]string{"missing-key"}, nil))
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("required key missing-key")))
+}
+
+func TestReadHTTPSource(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"http-key": "http-val", "invalid key!": "dropped"}`)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// Success: valid keys are merged, invalid ones dropped.
+	config, err := ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{{Kind: KindHTTP, Name: srv.URL + "/config"}}, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["http-key"]).To(gomega.Equal("http-val"))
+	g.Expect(config).ToNot(gomega.HaveKey("invalid key!"))
+	g.Expect(config[ConfigSources]).To(gomega.ContainSubstring(`"kind":"http"`))
+
+	// 404 means the endpoint has no contribution, not an error.
+	config, err = ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{{Kind: KindHTTP, Name: srv.URL + "/missing"}}, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).ToNot(gomega.HaveKey("http-key"))
+
+	// A stalled endpoint fails once the client timeout expires.
+	_, _, err = readHTTPSource(context.Background(), logger,
+		ConfigSource{Kind: KindHTTP, Name: srv.URL + "/slow"},
+		resolveOptions{httpClient: &http.Client{Timeout: 50 * time.Millisecond}})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestResolveConfigurationsMetrics(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	// cm-b overrides shared-key from cm-a and tries to set denied-key, which
+	// the deny list drops.
+	for i, name := range []string{"cm-a", "cm-b"} {
+		data := map[string]string{"shared-key": fmt.Sprintf("val-%d", i)}
+		if name == "cm-b" {
+			data["denied-key"] = "nope"
+		}
+		_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: name},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	m := MetricsProvider()
+	config, err := ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{
+			{Kind: KindConfigMap, Namespace: testNS, Name: "cm-a"},
+			{Kind: KindConfigMap, Namespace: testNS, Name: "cm-b"},
+		}, nil, []string{"denied-key"}, WithMetrics(m))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["shared-key"]).To(gomega.Equal("val-1"))
+
+	g.Expect(testutil.ToFloat64(m.ConfigOverrides.WithLabelValues(KindConfigMap))).To(gomega.Equal(1.0))
+	g.Expect(testutil.ToFloat64(m.ConfigOverridesDenied.WithLabelValues(KindConfigMap))).To(gomega.Equal(1.0))
+}
+
+func TestReadConfigSourceInvalidKind(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	_, _, err := ReadConfigSource(context.Background(), logger, clients, "nodename", ConfigSource{Kind: "bogus"})
+	g.Expect(errors.Is(err, ErrInvalidSourceKind)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring(`"bogus"`))
+
+	// The typed error also surfaces through ResolveConfigurations.
+	_, err = ResolveConfigurations(context.Background(), logger, clients, "nodename", []ConfigSource{{Kind: "bogus"}}, nil, nil)
+	g.Expect(errors.Is(err, ErrInvalidSourceKind)).To(gomega.BeTrue())
+}
+
+func TestReadNodeConfigsWithCNCSelector(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	_, err := clients.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodename", Labels: map[string]string{"a": "b"}},
+	}, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	// Two CNCs match the node; they differ in their own labels.
+	for _, cnc := range []struct{ name, tier, val string }{
+		{"cnc-prod", "production", "prod-val"},
+		{"cnc-dev", "dev", "dev-val"},
+	} {
+		_, err := clients.CiliumV2().CiliumNodeConfigs(testNS).Create(context.Background(), &ciliumv2.CiliumNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNS,
+				Name:      cnc.name,
+				Labels:    map[string]string{"tier": cnc.tier},
+			},
+			Spec: ciliumv2.CiliumNodeConfigSpec{
+				Defaults:     map[string]string{"key": cnc.val},
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}},
+			},
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	// Without a selector, both CNCs apply; "cnc-prod" wins lexicographically.
+	config, _, err := readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "", "", CNCPriorityName)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.Equal(map[string]string{"key": "prod-val"}))
+
+	// The CNC-object selector pre-filters before node matching.
+	config, sources, err := readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "", "tier=dev", CNCPriorityName)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.Equal(map[string]string{"key": "dev-val"}))
+	g.Expect(sources).To(gomega.HaveLen(1))
+
+	// An invalid selector is a hard error.
+	_, _, err = readNodeConfigsAllVersions(context.Background(), logger, clients, "nodename", testNS, "", "tier==!", CNCPriorityName)
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestChangedKeys(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+	dir := t.TempDir()
+
+	onDisk := map[string]string{
+		"stable-key":  "same",
+		"changed-key": "old-value",
+		"removed-key": "going-away",
+	}
+	g.Expect(WriteConfigurations(context.Background(), logger, dir, onDisk)).To(gomega.BeNil())
+
+	// Identical data: nothing to do.
+	changed, err := ChangedKeys(dir, onDisk)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(changed).To(gomega.BeEmpty())
+
+	// Changed, added and removed keys are all reported, sorted.
+	changed, err = ChangedKeys(dir, map[string]string{
+		"stable-key":  "same",
+		"changed-key": "new-value",
+		"added-key":   "brand-new",
+	})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(changed).To(gomega.Equal([]string{"added-key", "changed-key", "removed-key"}))
+
+	// A directory with nothing materialized reports every key as changed.
+	changed, err = ChangedKeys(t.TempDir(), map[string]string{"some-key": "val"})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(changed).To(gomega.Equal([]string{"some-key"}))
+}
+
+func TestResolveConfigurationsSchemaMigrations(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	for _, cm := range []struct {
+		name string
+		data map[string]string
+	}{
+		// An old-schema source still using the deprecated key name.
+		{"old-cm", map[string]string{
+			SchemaVersionKey: "1",
+			"legacy-lb-mode": "dsr",
+		}},
+		// A new-schema source; its keys must pass through untouched.
+		{"new-cm", map[string]string{
+			SchemaVersionKey: "2",
+			"bpf-lb-mode":    "snat",
+			"legacy-lb-mode": "not-renamed",
+		}},
+	} {
+		_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: cm.name},
+			Data:       cm.data,
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	migrations := map[string]map[string]string{
+		"1": {"legacy-lb-mode": "bpf-lb-mode"},
+	}
+
+	config, err := ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{{Kind: KindConfigMap, Namespace: testNS, Name: "old-cm"}}, nil, nil,
+		WithSchemaMigrations(migrations))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["bpf-lb-mode"]).To(gomega.Equal("dsr"))
+	g.Expect(config).ToNot(gomega.HaveKey("legacy-lb-mode"))
+
+	config, err = ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{{Kind: KindConfigMap, Namespace: testNS, Name: "new-cm"}}, nil, nil,
+		WithSchemaMigrations(migrations))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["bpf-lb-mode"]).To(gomega.Equal("snat"))
+	g.Expect(config["legacy-lb-mode"]).To(gomega.Equal("not-renamed"))
+}
+
+func TestResolveConfigurationsSealedSource(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	// A fake seal: the blob is a JSON object prefixed with "sealed:".
+	unseal := func(blob []byte) (map[string]string, error) {
+		rest, ok := strings.CutPrefix(string(blob), "sealed:")
+		if !ok {
+			return nil, fmt.Errorf("blob is not sealed")
+		}
+		var data map[string]string
+		if err := json.Unmarshal([]byte(rest), &data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "sealed-cm"},
+		Data: map[string]string{
+			"sealed-config": `sealed:{"secret-key": "secret-val", "debug": "true"}`,
+		},
+	}, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	sources := []ConfigSource{{Kind: KindSealedConfigMap, Namespace: testNS, Name: "sealed-cm"}}
+
+	config, err := ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		sources, nil, nil, WithUnsealFunc(unseal))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config["secret-key"]).To(gomega.Equal("secret-val"))
+	g.Expect(config["debug"]).To(gomega.Equal("true"))
+
+	// Without an unseal function the sealed source is a hard error.
+	_, err = ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		sources, nil, nil)
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("unseal function")))
+
+	// A blob the unseal function rejects surfaces the error.
+	_, err = clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: "garbage-cm"},
+		Data:       map[string]string{"sealed-config": "not sealed at all"},
+	}, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	_, err = ResolveConfigurations(
+		context.Background(), logger, clients, "nodename",
+		[]ConfigSource{{Kind: KindSealedConfigMap, Namespace: testNS, Name: "garbage-cm"}},
+		nil, nil, WithUnsealFunc(unseal))
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("failed to unseal")))
+}
+
+func TestStripMetadataKeys(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	in := map[string]string{
+		"real-key":             "val",
+		"another-key":          "val2",
+		ConfigSources:          `[{"kind":"config-map"}]`,
+		ConfigSourcesOverrides: `{"allowConfigKeys":null,"denyConfigKeys":null}`,
+	}
+
+	out := StripMetadataKeys(in)
+	g.Expect(out).To(gomega.Equal(map[string]string{
+		"real-key":    "val",
+		"another-key": "val2",
+	}))
+
+	// The input is left untouched.
+	g.Expect(in).To(gomega.HaveKey(ConfigSources))
+	g.Expect(in).To(gomega.HaveKey(ConfigSourcesOverrides))
+	g.Expect(in).To(gomega.HaveLen(4))
+}
+
+func TestReadConfigMapGlob(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	for _, cm := range []struct {
+		name string
+		data map[string]string
+	}{
+		{"cilium-config-shard-b", map[string]string{"shared-key": "from-b", "b-key": "b"}},
+		{"cilium-config-shard-a", map[string]string{"shared-key": "from-a", "a-key": "a"}},
+		{"unrelated", map[string]string{"unrelated-key": "x"}},
+	} {
+		_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: cm.name},
+			Data:       cm.data,
+		}, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	config, sources, err := ReadConfigSource(context.Background(), logger, clients, "nodename",
+		ConfigSource{Kind: KindConfigMap, Namespace: testNS, Name: "cilium-config-shard-*"})
+	g.Expect(err).To(gomega.BeNil())
+	// Lexicographic order: shard-b merges after shard-a and wins shared keys.
+	g.Expect(config).To(gomega.Equal(map[string]string{
+		"shared-key": "from-b",
+		"a-key":      "a",
+		"b-key":      "b",
+	}))
+	g.Expect(sources).To(gomega.HaveLen(2))
+	g.Expect(sources[0].Name).To(gomega.Equal("cilium-config-shard-a"))
+	g.Expect(sources[1].Name).To(gomega.Equal("cilium-config-shard-b"))
+
+	// A pattern matching nothing is not an error.
+	config, _, err = ReadConfigSource(context.Background(), logger, clients, "nodename",
+		ConfigSource{Kind: KindConfigMap, Namespace: testNS, Name: "no-such-*"})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.BeEmpty())
+}
+
+func TestWatchConfigurations(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fakeNode := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "nodename",
+			Labels: map[string]string{"a": "b"},
+		},
+	}
+	_, err := clients.CoreV1().Nodes().Create(ctx, &fakeNode, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "watched-cm",
+		},
+		Data: map[string]string{
+			"cm-key": "v1",
+		},
+	}
+	_, err = clients.CoreV1().ConfigMaps(testNS).Create(ctx, &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	cnc := ciliumv2.CiliumNodeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "watched-cnc",
+		},
+		Spec: ciliumv2.CiliumNodeConfigSpec{
+			Defaults: map[string]string{
+				"cnc-key": "cnc-v1",
+			},
+			NodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"a": "b"},
+			},
+		},
+	}
+	_, err = clients.CiliumV2().CiliumNodeConfigs(testNS).Create(ctx, &cnc, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	cncAlpha := ciliumv2alpha1.CiliumNodeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "watched-cnc-alpha",
+		},
+		Spec: ciliumv2alpha1.CiliumNodeConfigSpec{
+			Defaults: map[string]string{
+				"cnc-alpha-key": "alpha-v1",
+			},
+			NodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"a": "b"},
+			},
+		},
+	}
+	_, err = clients.CiliumV2alpha1().CiliumNodeConfigs(testNS).Create(ctx, &cncAlpha, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	results := make(chan map[string]string, 16)
+	err = WatchConfigurations(ctx, logger, clients, "nodename",
+		[]ConfigSource{
+			{Kind: KindConfigMap, Namespace: testNS, Name: "watched-cm"},
+			{Kind: KindNodeConfig, Namespace: testNS},
+		},
+		nil, nil,
+		10*time.Millisecond,
+		func(config map[string]string) { results <- config },
+	)
+	g.Expect(err).To(gomega.BeNil())
+
+	// The initial resolution is delivered before WatchConfigurations returns.
+	var config map[string]string
+	g.Expect(results).To(gomega.Receive(&config))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cm-key", "v1"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cnc-key", "cnc-v1"))
+
+	// Updating the watched ConfigMap triggers a re-resolution.
+	cm.Data["cm-key"] = "v2"
+	_, err = clients.CoreV1().ConfigMaps(testNS).Update(ctx, &cm, metav1.UpdateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Eventually(results).Should(gomega.Receive(&config))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cm-key", "v2"))
+
+	// So does updating a CiliumNodeConfig; the callback sees the merged result.
+	cnc.Spec.Defaults["cnc-key"] = "cnc-v2"
+	_, err = clients.CiliumV2().CiliumNodeConfigs(testNS).Update(ctx, &cnc, metav1.UpdateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Eventually(results).Should(gomega.Receive(&config))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cm-key", "v2"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cnc-key", "cnc-v2"))
+
+	// The v2alpha1 CiliumNodeConfigs are watched too.
+	cncAlpha.Spec.Defaults["cnc-alpha-key"] = "alpha-v2"
+	_, err = clients.CiliumV2alpha1().CiliumNodeConfigs(testNS).Update(ctx, &cncAlpha, metav1.UpdateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Eventually(results).Should(gomega.Receive(&config))
+	g.Expect(config).To(gomega.HaveKeyWithValue("cnc-alpha-key", "alpha-v2"))
+
+	// A ConfigMap the sources do not reference is ignored.
+	unrelated := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "unrelated",
+		},
+		Data: map[string]string{"other": "value"},
+	}
+	_, err = clients.CoreV1().ConfigMaps(testNS).Create(ctx, &unrelated, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Consistently(results, "100ms").ShouldNot(gomega.Receive())
+}
+
+func TestResolveConfigurationsDeprecatedKeys(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "deprecated-cm",
+		},
+		Data: map[string]string{
+			"legacy-lb-mode": "snat",
+			"old-key":        "old-val",
+			"new-key":        "new-val",
+		},
+	}
+	_, err := clients.CoreV1().ConfigMaps(testNS).Create(context.Background(), &cm, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	deprecated := map[string]string{
+		"legacy-lb-mode": "bpf-lb-mode",
+		"old-key":        "new-key",
+	}
+	sources := []ConfigSource{{Kind: KindConfigMap, Namespace: testNS, Name: "deprecated-cm"}}
+
+	// Warn-only: deprecated keys stay untouched.
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		sources, nil, nil,
+		WithDeprecatedKeys(deprecated, false))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("legacy-lb-mode", "snat"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("old-key", "old-val"))
+	g.Expect(config).ToNot(gomega.HaveKey("bpf-lb-mode"))
+
+	// Auto-migrate: the value moves to the replacement key; a deprecated key
+	// set alongside its replacement is dropped and the replacement wins.
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		sources, nil, nil,
+		WithDeprecatedKeys(deprecated, true))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("bpf-lb-mode", "snat"))
+	g.Expect(config).ToNot(gomega.HaveKey("legacy-lb-mode"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("new-key", "new-val"))
+	g.Expect(config).ToNot(gomega.HaveKey("old-key"))
+}
+
+func TestReadNodeOverridesPrefixes(t *testing.T) {
+	logger := hivetest.Logger(t)
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	fakeNode := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nodename",
+			Annotations: map[string]string{
+				"config.cilium.io/cilium-only": "from-cilium",
+				"config.cilium.io/shared-key":  "from-cilium",
+				"config.acme.io/acme-only":     "from-acme",
+				"config.acme.io/shared-key":    "from-acme",
+			},
+		},
+	}
+	_, err := clients.CoreV1().Nodes().Create(context.Background(), &fakeNode, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	sources := []ConfigSource{{Kind: KindNode, Name: "nodename"}}
+
+	// Without the option only the default prefix is read.
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		sources, nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("cilium-only", "from-cilium"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("shared-key", "from-cilium"))
+	g.Expect(config).ToNot(gomega.HaveKey("acme-only"))
+
+	// With layered prefixes, keys from both are read and the later prefix
+	// wins conflicting keys.
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		sources, nil, nil,
+		WithNodeOverridePrefixes([]string{"config.cilium.io", "config.acme.io"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("cilium-only", "from-cilium"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("acme-only", "from-acme"))
+	g.Expect(config).To(gomega.HaveKeyWithValue("shared-key", "from-acme"))
+
+	// Reversing the order reverses the precedence.
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		sources, nil, nil,
+		WithNodeOverridePrefixes([]string{"config.acme.io", "config.cilium.io"}))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("shared-key", "from-cilium"))
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	schema := map[string]KeySpec{
+		"debug":       {Type: KeyTypeBool},
+		"mtu":         {Type: KeyTypeInt},
+		"cluster":     {Type: KeyTypeString},
+		"bpf-lb-mode": {Type: KeyTypeEnum, Values: []string{"snat", "dsr", "hybrid"}},
+	}
+
+	// A conforming config produces no errors; provenance keys are skipped.
+	errs := ValidateAgainstSchema(map[string]string{
+		"debug":       "true",
+		"mtu":         "1500",
+		"cluster":     "default",
+		"bpf-lb-mode": "dsr",
+		ConfigSources: `[{"kind":"config-map"}]`,
+	}, schema)
+	g.Expect(errs).To(gomega.BeEmpty())
+
+	// One error per violation, ordered by key.
+	errs = ValidateAgainstSchema(map[string]string{
+		"debug":       "yes-please",
+		"mtu":         "jumbo",
+		"bpf-lb-mode": "magic",
+		"surprise":    "value",
+	}, schema)
+	g.Expect(errs).To(gomega.HaveLen(4))
+	g.Expect(errs[0].Error()).To(gomega.ContainSubstring(`value "magic" is not one of`))
+	g.Expect(errs[1].Error()).To(gomega.ContainSubstring(`value "yes-please" is not a boolean`))
+	g.Expect(errs[2].Error()).To(gomega.ContainSubstring(`value "jumbo" is not an integer`))
+	g.Expect(errs[3].Error()).To(gomega.ContainSubstring("unknown key surprise"))
+}
+
+// fakeDynamicResource serves a single unstructured object by name; everything
+// else of the dynamic interface is left unimplemented.
+type fakeDynamicResource struct {
+	dynamic.NamespaceableResourceInterface
+	obj *unstructured.Unstructured
+}
+
+func (f fakeDynamicResource) Namespace(string) dynamic.ResourceInterface { return f }
+
+func (f fakeDynamicResource) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	if f.obj == nil || f.obj.GetName() != name {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "ciliumconfigs"}, name)
+	}
+	return f.obj, nil
+}
+
+type fakeDynamicClient struct {
+	resource fakeDynamicResource
+}
+
+func (f fakeDynamicClient) Resource(schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return f.resource
+}
+
+func TestReadCRDStatus(t *testing.T) {
+	logger := hivetest.Logger(t)
+	testNS := "test-ns"
+	g := gomega.NewWithT(t)
+	clients, _ := k8sClient.NewFakeClientset(logger)
+
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.io/v1alpha1",
+		"kind":       "CiliumConfig",
+		"metadata": map[string]any{
+			"name":      "per-node",
+			"namespace": testNS,
+		},
+		"status": map[string]any{
+			"computed": map[string]any{
+				"overrides": map[string]any{
+					"crd-key":  "crd-val",
+					"bad key!": "dropped",
+				},
+			},
+		},
+	}}
+	dyn := fakeDynamicClient{resource: fakeDynamicResource{obj: obj}}
+
+	source := ConfigSource{
+		Kind:       KindCRDStatus,
+		Namespace:  testNS,
+		Name:       "per-node",
+		Resource:   "ciliumconfigs.v1alpha1.example.io",
+		StatusPath: "computed.overrides",
+	}
+
+	// Operator-computed overrides participate in the merge; invalid keys are
+	// dropped like for any other source.
+	config, err := ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		[]ConfigSource{source}, nil, nil,
+		WithDynamicClient(dyn))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).To(gomega.HaveKeyWithValue("crd-key", "crd-val"))
+	g.Expect(config).ToNot(gomega.HaveKey("bad key!"))
+	g.Expect(config[ConfigSources]).To(gomega.ContainSubstring(KindCRDStatus))
+
+	// Without a dynamic client the source cannot be read.
+	_, err = ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		[]ConfigSource{source}, nil, nil)
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("dynamic client")))
+
+	// A missing object is tolerated, like a missing ConfigMap.
+	missing := source
+	missing.Name = "no-such-object"
+	config, err = ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		[]ConfigSource{missing}, nil, nil,
+		WithDynamicClient(dyn))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(config).ToNot(gomega.HaveKey("crd-key"))
+
+	// A status path not holding a string map is an error.
+	notAMap := source
+	notAMap.StatusPath = "computed"
+	_, err = ResolveConfigurations(context.Background(), logger, clients, "nodename",
+		[]ConfigSource{notAMap}, nil, nil,
+		WithDynamicClient(dyn))
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("string map")))
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/cilium/cilium/pkg/k8s/client"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/time"
+)
+
+// WatchConfigurations resolves the configuration like ResolveConfigurations
+// and then keeps it fresh: it watches the Kubernetes objects the sources
+// reference (ConfigMaps, the Node, CiliumNodeConfigs) and invokes callback
+// with the freshly merged result whenever one of them changes. Changes
+// arriving within the debounce window after the first one are coalesced into
+// a single re-resolution.
+//
+// The callback is invoked once with the initial resolution before this
+// function returns, and from a single goroutine afterwards, until ctx is
+// cancelled. Sources without a watchable cluster object (KindHTTP, KindPod)
+// are still read on every re-resolution, but do not trigger one themselves.
+//
+// An error is returned if a watch cannot be established or the initial
+// resolution fails; later re-resolution failures are logged and the previous
+// result stays in effect.
+func WatchConfigurations(ctx context.Context, logger *slog.Logger, client client.Clientset, nodeName string, sources []ConfigSource, allowConfigKeys, denyConfigKeys []string, debounce time.Duration, callback func(map[string]string), opts ...ResolveOption) error {
+	// kick has capacity 1; a pending kick subsumes any further ones.
+	kick := make(chan struct{}, 1)
+
+	watchingNode := false
+	watchNode := func() error {
+		if watchingNode {
+			return nil
+		}
+		watchingNode = true
+		return watchKicks(ctx, logger, kick, func() (watch.Interface, error) {
+			return client.CoreV1().Nodes().Watch(ctx, metav1.ListOptions{})
+		}, func(obj metav1.Object) bool {
+			return obj.GetName() == nodeName
+		})
+	}
+
+	for _, source := range sources {
+		switch source.Kind {
+		case KindConfigMap, KindSealedConfigMap:
+			name := source.Name
+			namespaces := source.Namespaces
+			if len(namespaces) == 0 {
+				namespaces = []string{source.Namespace}
+			}
+			for _, namespace := range namespaces {
+				err := watchKicks(ctx, logger, kick, func() (watch.Interface, error) {
+					return client.CoreV1().ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{})
+				}, func(obj metav1.Object) bool {
+					return configMapNameMatches(name, obj.GetName())
+				})
+				if err != nil {
+					return fmt.Errorf("failed to watch ConfigMaps in namespace %s: %w", namespace, err)
+				}
+			}
+		case KindNode:
+			if err := watchNode(); err != nil {
+				return fmt.Errorf("failed to watch Node %s: %w", nodeName, err)
+			}
+		case KindNodeConfig:
+			name := source.Name
+			relevant := func(obj metav1.Object) bool {
+				return name == "" || obj.GetName() == name
+			}
+			// Node configs are read from both the v2 and v2alpha1 CRDs, so
+			// both are watched. As in readNodeConfigsAllVersions, one version
+			// being unavailable is tolerated as long as the other works.
+			// TODO depreciate CNC on v2alpha1 https://github.com/cilium/cilium/issues/31982
+			errv2 := watchKicks(ctx, logger, kick, func() (watch.Interface, error) {
+				return client.CiliumV2().CiliumNodeConfigs(source.Namespace).Watch(ctx, metav1.ListOptions{})
+			}, relevant)
+			errv2alpha1 := watchKicks(ctx, logger, kick, func() (watch.Interface, error) {
+				return client.CiliumV2alpha1().CiliumNodeConfigs(source.Namespace).Watch(ctx, metav1.ListOptions{})
+			}, relevant)
+			if errv2 != nil && errv2alpha1 != nil {
+				return fmt.Errorf("failed to watch CiliumNodeConfigs in namespace %s: %w and %w", source.Namespace, errv2, errv2alpha1)
+			}
+			if errv2 != nil {
+				logger.Warn(
+					"Could not watch CiliumNodeConfig v2, watching v2alpha1 only",
+					logfields.Error, errv2,
+				)
+			}
+			if errv2alpha1 != nil {
+				logger.Warn(
+					"Could not watch CiliumNodeConfig v2alpha1, watching v2 only",
+					logfields.Error, errv2alpha1,
+				)
+			}
+			// Which CiliumNodeConfigs apply depends on the node's labels,
+			// so node changes must re-resolve too.
+			if err := watchNode(); err != nil {
+				return fmt.Errorf("failed to watch Node %s: %w", nodeName, err)
+			}
+		default:
+			logger.Debug(
+				"Config source kind is not watchable; changes are only picked up on re-resolution",
+				logfields.ConfigSource, source.String(),
+			)
+		}
+	}
+
+	resolve := func() (map[string]string, error) {
+		return ResolveConfigurations(ctx, logger, client, nodeName, sources, allowConfigKeys, denyConfigKeys, opts...)
+	}
+
+	config, err := resolve()
+	if err != nil {
+		return err
+	}
+	callback(config)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-kick:
+			}
+			if debounce > 0 {
+				// Coalesce further changes arriving within the debounce
+				// window, measured from the first change.
+				timer := time.NewTimer(debounce)
+				for quiet := false; !quiet; {
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-kick:
+					case <-timer.C:
+						quiet = true
+					}
+				}
+			}
+			config, err := resolve()
+			if err != nil {
+				logger.Warn(
+					"Failed to re-resolve configuration, keeping previous result",
+					logfields.Error, err,
+				)
+				continue
+			}
+			callback(config)
+		}
+	}()
+
+	return nil
+}
+
+// watchKicks establishes the watch and spawns a goroutine forwarding every
+// relevant event as a non-blocking send on kick. The watch is re-established
+// when the API server ends it, and stopped when ctx is cancelled.
+func watchKicks(ctx context.Context, logger *slog.Logger, kick chan<- struct{}, establish func() (watch.Interface, error), relevant func(metav1.Object) bool) error {
+	w, err := establish()
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer func() {
+			if w != nil {
+				w.Stop()
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.ResultChan():
+				if !ok {
+					w, err = establish()
+					if err != nil {
+						logger.Warn(
+							"Could not re-establish config source watch, changes will no longer be observed",
+							logfields.Error, err,
+						)
+						w = nil
+						return
+					}
+					continue
+				}
+				if ev.Type == watch.Error {
+					continue
+				}
+				if obj, err := meta.Accessor(ev.Object); err == nil && !relevant(obj) {
+					continue
+				}
+				select {
+				case kick <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// configMapNameMatches reports whether a ConfigMap name matches the source's
+// configured name, which may be a glob pattern as in expandConfigMapNames.
+func configMapNameMatches(pattern, name string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == name
+	}
+	matched, err := filepath.Match(pattern, name)
+	return err == nil && matched
+}